@@ -0,0 +1,47 @@
+package alteration
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+// AvifEnc is an alteration that can apply avifenc to a resource
+// to convert a PNG or JPEG image into an AVIF image.
+// Bin is the path to avifenc executable.
+// Quality is the quality of the AVIF image (from 0 to 100).
+type AvifEnc struct {
+	Bin     string
+	Quality int
+}
+
+// NewAvifEnc creates a new AvifEnc alteration.
+func NewAvifEnc(bin string, quality int) AvifEnc {
+	return AvifEnc{
+		Bin:     bin,
+		Quality: quality,
+	}
+}
+
+// Alter runs avifenc on a resource and returns the AVIF image.
+// avifenc guesses the format of the input from its extension,
+// so the temporary input file is named after the type of the content.
+func (ae AvifEnc) Alter(r resource.Resource) (resource.Resource, error) {
+	c, err := r.Dump()
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	suffix := ".png"
+	if http.DetectContentType(c) == "image/jpeg" {
+		suffix = ".jpg"
+	}
+
+	return ExecCommand(
+		ae.Bin,
+		"-q", strconv.Itoa(ae.Quality),
+		TmpInputFile{Resource: resource.NewBytes(c), Suffix: suffix},
+		TmpOutputFile{Suffix: ".avif"},
+	)
+}
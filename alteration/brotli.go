@@ -0,0 +1,28 @@
+package alteration
+
+import (
+	"strconv"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+// Brotli is an alteration that can apply brotli to a resource.
+// Bin is the path to brotli executable.
+// Quality is the compression level (from 0 to 11)
+type Brotli struct {
+	Bin     string
+	Quality int
+}
+
+// NewBrotli creates a new Brotli alteration.
+func NewBrotli(bin string, quality int) Brotli {
+	return Brotli{
+		Bin:     bin,
+		Quality: quality,
+	}
+}
+
+// Alter runs brotli on a resource and returns the compressed one.
+func (br Brotli) Alter(r resource.Resource) (resource.Resource, error) {
+	return ExecCommand(br.Bin, "-c", "-q", strconv.Itoa(br.Quality), TmpInputFile{Resource: r})
+}
@@ -0,0 +1,37 @@
+package alteration
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+func TestBrotli(t *testing.T) {
+	bin := os.Getenv("STATIX_TEST_BROTLI_BIN")
+
+	if bin == "" {
+		t.Skip("STATIX_TEST_BROTLI_BIN is not set")
+	}
+
+	input := resource.NewString("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	a := NewBrotli(bin, 11)
+
+	output, err := a.Alter(input)
+
+	if err != nil {
+		t.Error("could not alter resource")
+	}
+
+	outputBytes, err := output.Dump()
+
+	if err != nil {
+		t.Error("could not dump content")
+	}
+
+	inputBytes, _ := input.Dump()
+
+	if len(inputBytes) <= len(outputBytes) {
+		t.Error("resource has not been compressed")
+	}
+}
@@ -4,9 +4,10 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/sarulabs/statix/helpers"
 	"github.com/sarulabs/statix/resource"
@@ -26,6 +27,26 @@ type TmpOutputFile struct {
 	Suffix string
 }
 
+// sourceInput returns the argument giving the content of `r` to a compiler.
+// If `r` is a resource.File, the path of the file is returned,
+// so the compiler can resolve the relative imports.
+// Otherwise, for example after another alteration, the content is streamed
+// in a TmpInputFile with the `suffix`. The directory of the Source
+// (see resource.MetadataOf) is also returned, or an empty string
+// if the Source is not in the host file system.
+func sourceInput(r resource.Resource, suffix string) (interface{}, string) {
+	if f, ok := r.(*resource.File); ok {
+		return f.Path, filepath.Dir(f.Path)
+	}
+
+	dir := ""
+	if m := resource.MetadataOf(r); m.Source != "" && m.FS == nil {
+		dir = filepath.Dir(m.Source)
+	}
+
+	return TmpInputFile{Resource: r, Suffix: suffix}, dir
+}
+
 // ExecCommand executes a command that returns a resource.
 // Arguments should be strings, except for two.
 // - one may be a TmpInputFile
@@ -33,7 +54,8 @@ type TmpOutputFile struct {
 // That is because you will often need a temporary input file
 // and a temporary output file to  generate a resource.
 // The returned Resource content is the command standard output
-// except when a TmpOutputFile is used.
+// except when a TmpOutputFile is used. It is a resource.TempFile,
+// so the output is not loaded in memory.
 func ExecCommand(name string, args ...interface{}) (resource.Resource, error) {
 	parsedArgs, err := parseArgs(args...)
 	if err != nil {
@@ -53,12 +75,14 @@ func ExecCommand(name string, args ...interface{}) (resource.Resource, error) {
 			os.Remove(inputFile.Name())
 		}()
 
-		content, err := parsedArgs.tmpInputFile.Resource.Dump()
+		// the content is streamed, so the resource is not loaded in memory
+		rc, err := resource.Open(parsedArgs.tmpInputFile.Resource)
 		if err != nil {
 			return &resource.Empty{}, err
 		}
 
-		_, err = inputFile.Write(content)
+		_, err = io.Copy(inputFile, rc)
+		rc.Close()
 		if err != nil {
 			return &resource.Empty{}, err
 		}
@@ -70,10 +94,7 @@ func ExecCommand(name string, args ...interface{}) (resource.Resource, error) {
 		if err != nil {
 			return &resource.Empty{}, err
 		}
-		defer func() {
-			outputFile.Close()
-			os.Remove(outputFile.Name())
-		}()
+		outputFile.Close()
 	}
 
 	// update args with the name of temporary files
@@ -86,27 +107,39 @@ func ExecCommand(name string, args ...interface{}) (resource.Resource, error) {
 		}
 	}
 
+	// the standard output is written in a temporary file,
+	// so the result is not loaded in memory
+	stdoutFile, err := helpers.TempFile("", "statix_filter_", "")
+	if err != nil {
+		if outputFile != nil {
+			os.Remove(outputFile.Name())
+		}
+		return &resource.Empty{}, err
+	}
+	defer stdoutFile.Close()
+
 	// execute command
-	bufOut := bytes.NewBuffer(nil)
 	bufErr := bytes.NewBuffer(nil)
 	command := exec.Command(name, parsedArgs.args...)
-	command.Stdout = bufOut
+	command.Stdout = stdoutFile
 	command.Stderr = bufErr
 	err = command.Run()
+
+	if err != nil || parsedArgs.tmpOutputFile != nil {
+		os.Remove(stdoutFile.Name())
+	}
 	if err != nil {
+		if outputFile != nil {
+			os.Remove(outputFile.Name())
+		}
 		return &resource.Empty{}, fmt.Errorf("command error on (%s, %s) :\n%s", name, parsedArgs.args, bufErr.String())
 	}
 
 	if parsedArgs.tmpOutputFile == nil {
-		return resource.NewBytes(bufOut.Bytes()), nil
-	}
-
-	c, err := ioutil.ReadFile(outputFile.Name())
-	if err != nil {
-		return &resource.Empty{}, err
+		return resource.NewTempFile(stdoutFile.Name()), nil
 	}
 
-	return resource.NewBytes(c), nil
+	return resource.NewTempFile(outputFile.Name()), nil
 }
 
 const statixTmpInputFile = "{{STATIX_TMP_INPUT_FILE}}"
@@ -0,0 +1,72 @@
+package alteration
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+func TestExecCommandStdout(t *testing.T) {
+	bin, err := exec.LookPath("cat")
+	if err != nil {
+		t.Skip("cat is not available")
+	}
+
+	r, err := ExecCommand(bin, TmpInputFile{Resource: resource.NewString("stdout")})
+	if err != nil {
+		t.Fatal("could not execute the command", err)
+	}
+
+	f, ok := r.(*resource.TempFile)
+	if !ok {
+		t.Fatalf("the standard output should be a TempFile instead of %T", r)
+	}
+	defer f.Remove()
+
+	if _, ok := r.(resource.Opener); !ok {
+		t.Error("the output should be streamed")
+	}
+	if c, err := r.Dump(); err != nil || !bytes.Equal(c, []byte("stdout")) {
+		t.Error("the content should be the standard output", string(c), err)
+	}
+}
+
+func TestExecCommandOutputFile(t *testing.T) {
+	bin, err := exec.LookPath("cp")
+	if err != nil {
+		t.Skip("cp is not available")
+	}
+
+	r, err := ExecCommand(bin, TmpInputFile{Resource: resource.NewString("output")}, TmpOutputFile{Suffix: ".txt"})
+	if err != nil {
+		t.Fatal("could not execute the command", err)
+	}
+
+	f, ok := r.(*resource.TempFile)
+	if !ok {
+		t.Fatalf("the output file should be a TempFile instead of %T", r)
+	}
+
+	if c, err := r.Dump(); err != nil || !bytes.Equal(c, []byte("output")) {
+		t.Error("the content should be the output file", string(c), err)
+	}
+
+	f.Remove()
+	if _, err := os.Stat(f.Path); !os.IsNotExist(err) {
+		t.Error("the output file should be removed with the TempFile", err)
+	}
+}
+
+func TestExecCommandError(t *testing.T) {
+	bin, err := exec.LookPath("false")
+	if err != nil {
+		t.Skip("false is not available")
+	}
+
+	if _, err := ExecCommand(bin, TmpOutputFile{}); err == nil {
+		t.Error("the command error should be returned")
+	}
+}
@@ -0,0 +1,35 @@
+package alteration
+
+import (
+	"strconv"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+// CWebp is an alteration that can apply cwebp to a resource
+// to convert a PNG or JPEG image into a WebP image.
+// Bin is the path to cwebp executable.
+// Quality is the quality of the WebP image (from 0 to 100).
+type CWebp struct {
+	Bin     string
+	Quality int
+}
+
+// NewCWebp creates a new CWebp alteration.
+func NewCWebp(bin string, quality int) CWebp {
+	return CWebp{
+		Bin:     bin,
+		Quality: quality,
+	}
+}
+
+// Alter runs cwebp on a resource and returns the WebP image.
+func (cw CWebp) Alter(r resource.Resource) (resource.Resource, error) {
+	return ExecCommand(
+		cw.Bin,
+		"-quiet",
+		"-q", strconv.Itoa(cw.Quality),
+		TmpInputFile{Resource: r},
+		"-o", TmpOutputFile{Suffix: ".webp"},
+	)
+}
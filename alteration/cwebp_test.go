@@ -0,0 +1,35 @@
+package alteration
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+func TestCWebp(t *testing.T) {
+	bin := os.Getenv("STATIX_TEST_CWEBP_BIN")
+
+	if bin == "" {
+		t.Skip("STATIX_TEST_CWEBP_BIN is not set")
+	}
+
+	input := resource.NewFile("testFiles/test.png")
+	a := NewCWebp(bin, 75)
+
+	output, err := a.Alter(input)
+
+	if err != nil {
+		t.Error("could not alter resource")
+	}
+
+	outputBytes, err := output.Dump()
+
+	if err != nil {
+		t.Error("could not dump content")
+	}
+
+	if len(outputBytes) == 0 {
+		t.Error("the webp image should not be empty")
+	}
+}
@@ -0,0 +1,157 @@
+package alteration
+
+import (
+	"encoding/base64"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/sarulabs/statix/helpers"
+	"github.com/sarulabs/statix/resource"
+)
+
+// DataURI is an alteration that replaces the url(...) references of a stylesheet
+// by data URIs, if the referenced file is not larger than MaxSize bytes.
+// It saves a request for small icons and fonts.
+// The references are relative to the Source of the resource (see resource.MetadataOf),
+// for example a resource.File or a resource.FSFile, even after other alterations.
+// For the resources without Source, they are relative to Dir.
+// If Dir is empty, the content of these resources is returned as it is.
+// The references that are absolute, that are urls or that can not be read are not replaced.
+type DataURI struct {
+	MaxSize int64
+	Dir     string
+}
+
+// NewDataURI creates a new DataURI alteration.
+func NewDataURI(maxSize int64) DataURI {
+	return DataURI{
+		MaxSize: maxSize,
+	}
+}
+
+// cssURLRegexp matches the url(...) references of a stylesheet.
+var cssURLRegexp = regexp.MustCompile(`url\(\s*(["']?)([^"')]+)(["']?)\s*\)`)
+
+// dataURITypes contains the media types of the fonts,
+// that are not in the table of the mime package.
+var dataURITypes = map[string]string{
+	".eot":   "application/vnd.ms-fontobject",
+	".otf":   "font/otf",
+	".ttf":   "font/ttf",
+	".woff":  "font/woff",
+	".woff2": "font/woff2",
+}
+
+// Alter replaces the references to small files by data URIs.
+func (du DataURI) Alter(r resource.Resource) (resource.Resource, error) {
+	var read func(string) ([]byte, bool)
+
+	m := resource.MetadataOf(r)
+
+	switch {
+	case m.Source != "" && m.FS != nil:
+		read = du.fsReader(m.FS, path.Dir(helpers.FSPath(m.Source)))
+	case m.Source != "":
+		read = du.fileReader(filepath.Dir(m.Source))
+	case du.Dir != "":
+		read = du.fileReader(du.Dir)
+	}
+
+	c, err := r.Dump()
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	if read == nil {
+		return resource.NewBytes(c), nil
+	}
+
+	c = cssURLRegexp.ReplaceAllFunc(c, func(m []byte) []byte {
+		sub := cssURLRegexp.FindSubmatch(m)
+		ref := strings.TrimSpace(string(sub[2]))
+
+		if string(sub[1]) != string(sub[3]) || !relativeReference(ref) {
+			return m
+		}
+
+		// the query and the fragment are not part of the file name
+		name := ref
+		if i := strings.IndexAny(name, "?#"); i >= 0 {
+			name = name[:i]
+		}
+
+		content, ok := read(name)
+		if !ok {
+			return m
+		}
+
+		return []byte("url(" + string(sub[1]) + dataURI(name, content) + string(sub[1]) + ")")
+	})
+
+	return resource.NewBytes(c), nil
+}
+
+// fileReader returns a function reading the files relative to `dir`
+// that are not larger than DataURI.MaxSize.
+func (du DataURI) fileReader(dir string) func(string) ([]byte, bool) {
+	return func(name string) ([]byte, bool) {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		info, err := os.Stat(filename)
+		if err != nil || info.IsDir() || info.Size() > du.MaxSize {
+			return nil, false
+		}
+		c, err := os.ReadFile(filename)
+		return c, err == nil
+	}
+}
+
+// fsReader returns a function reading the files of `fsys` relative to `dir`
+// that are not larger than DataURI.MaxSize.
+func (du DataURI) fsReader(fsys fs.FS, dir string) func(string) ([]byte, bool) {
+	return func(name string) ([]byte, bool) {
+		filename := path.Join(dir, name)
+		info, err := fs.Stat(fsys, filename)
+		if err != nil || info.IsDir() || info.Size() > du.MaxSize {
+			return nil, false
+		}
+		c, err := fs.ReadFile(fsys, filename)
+		return c, err == nil
+	}
+}
+
+// relativeReference checks if `ref` is a path relative to the stylesheet.
+func relativeReference(ref string) bool {
+	if ref == "" || strings.HasPrefix(ref, "/") || strings.HasPrefix(ref, "#") {
+		return false
+	}
+	// urls and data URIs have a scheme
+	if i := strings.Index(ref, ":"); i >= 0 && !strings.ContainsAny(ref[:i], "/?#") {
+		return false
+	}
+	return true
+}
+
+// dataURI returns the base64 data URI of the file `name` with the `content`.
+// Its media type is guessed from its extension, or from its content.
+func dataURI(name string, content []byte) string {
+	ext := strings.ToLower(path.Ext(name))
+
+	mediaType, ok := dataURITypes[ext]
+	if !ok {
+		mediaType = mime.TypeByExtension(ext)
+	}
+	if mediaType == "" {
+		mediaType = http.DetectContentType(content)
+	}
+	if i := strings.Index(mediaType, ";"); i >= 0 {
+		mediaType = strings.TrimSpace(mediaType[:i])
+	}
+
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(content)
+}
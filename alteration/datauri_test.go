@@ -0,0 +1,82 @@
+package alteration
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sarulabs/statix/helpers"
+	"github.com/sarulabs/statix/resource"
+)
+
+const testDataURICSS = `.a { background: url(img/a.svg); }
+.b { background: url("img/big.png"); }
+@font-face { src: url('fonts/f.woff2?v=1#x') format("woff2"); }
+.c { background: url(/img/a.svg), url(http://example.com/a.svg), url(data:image/png;base64,AA==), url(missing.png); }`
+
+func TestDataURI(t *testing.T) {
+	dir, _ := helpers.TempDir("", "statix_datauri_", "")
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "css/img"), 0777)
+	os.MkdirAll(filepath.Join(dir, "css/fonts"), 0777)
+	os.WriteFile(filepath.Join(dir, "css/main.css"), []byte(testDataURICSS), 0777)
+	os.WriteFile(filepath.Join(dir, "css/img/a.svg"), []byte("<svg/>"), 0777)
+	os.WriteFile(filepath.Join(dir, "css/img/big.png"), make([]byte, 20), 0777)
+	os.WriteFile(filepath.Join(dir, "css/fonts/f.woff2"), []byte("wOF2"), 0777)
+
+	expected := `.a { background: url(data:image/svg+xml;base64,PHN2Zy8+); }
+.b { background: url("img/big.png"); }
+@font-face { src: url('data:font/woff2;base64,d09GMg==') format("woff2"); }
+.c { background: url(/img/a.svg), url(http://example.com/a.svg), url(data:image/png;base64,AA==), url(missing.png); }`
+
+	output, err := NewDataURI(10).Alter(resource.NewFile(filepath.Join(dir, "css/main.css")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, _ := output.Dump(); string(c) != expected {
+		t.Error("wrong content for a File: ", string(c))
+	}
+
+	output, err = DataURI{MaxSize: 10, Dir: filepath.Join(dir, "css")}.Alter(resource.NewString(testDataURICSS))
+	if c, _ := output.Dump(); err != nil || string(c) != expected {
+		t.Error("the references of a String should be relative to Dir: ", string(c), err)
+	}
+
+	output, err = NewDataURI(10).Alter(resource.NewString(testDataURICSS))
+	if c, _ := output.Dump(); err != nil || string(c) != testDataURICSS {
+		t.Error("the references of a String without Dir should not be replaced: ", string(c), err)
+	}
+
+	fsys := fstest.MapFS{
+		"css/main.css": &fstest.MapFile{Data: []byte(`a { b: url(../img/x.gif) }`)},
+		"img/x.gif":    &fstest.MapFile{Data: []byte("GIF89a")},
+	}
+
+	output, err = NewDataURI(10).Alter(resource.NewFSFile(fsys, "css/main.css"))
+	if c, _ := output.Dump(); err != nil || !strings.Contains(string(c), "url(data:image/gif;base64,R0lGODlh)") {
+		t.Error("the references of an FSFile should be relative to its directory: ", string(c), err)
+	}
+
+	altered, _ := resource.ApplyAlteration(trimAlteration{}, resource.NewFile(filepath.Join(dir, "css/main.css")))
+	output, err = NewDataURI(10).Alter(altered)
+	if c, _ := output.Dump(); err != nil || string(c) != expected {
+		t.Error("the references should be relative to the source of an altered File: ", string(c), err)
+	}
+
+	output, err = NewDataURI(10).Alter(resource.NewCollection(resource.NewFSFile(fsys, "css/main.css")))
+	if c, _ := output.Dump(); err != nil || !strings.Contains(string(c), "url(data:image/gif;base64,R0lGODlh)") {
+		t.Error("the references of a Collection should be relative to its source: ", string(c), err)
+	}
+}
+
+// trimAlteration is an alteration removing the spaces around the content of a resource.
+type trimAlteration struct{}
+
+func (ta trimAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	c, err := r.Dump()
+	return resource.NewBytes(bytes.TrimSpace(c)), err
+}
@@ -0,0 +1,50 @@
+package alteration
+
+import (
+	"bytes"
+	"compress/gzip"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+// Gzip is an alteration that compresses a resource with gzip.
+// Level is the compression level (from 1 to 9, see compress/gzip).
+type Gzip struct {
+	Level int
+}
+
+// NewGzip creates a new Gzip alteration.
+func NewGzip(level int) Gzip {
+	return Gzip{
+		Level: level,
+	}
+}
+
+// Alter compresses a resource and returns the compressed one.
+// The gzip header does not contain any name or modification time,
+// so the same content always gives the same output.
+func (gz Gzip) Alter(r resource.Resource) (resource.Resource, error) {
+	c, err := r.Dump()
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	w, err := gzip.NewWriterLevel(buf, gz.Level)
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	_, err = w.Write(c)
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	err = w.Close()
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	return resource.NewBytes(buf.Bytes()), nil
+}
@@ -0,0 +1,47 @@
+package alteration
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+func TestGzip(t *testing.T) {
+	s := resource.NewString("aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa")
+	a := NewGzip(gzip.BestCompression)
+
+	r, err := a.Alter(s)
+
+	if err != nil {
+		t.Error("could not alter resource")
+	}
+
+	content, err := r.Dump()
+
+	if err != nil {
+		t.Error("could not dump content")
+	}
+
+	gr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		t.Error("content is not gzipped", err)
+		return
+	}
+
+	uncompressed, _ := ioutil.ReadAll(gr)
+	original, _ := s.Dump()
+
+	if !bytes.Equal(uncompressed, original) {
+		t.Error("uncompressed content is not correct", string(uncompressed))
+	}
+
+	r2, _ := a.Alter(s)
+	content2, _ := r2.Dump()
+
+	if !bytes.Equal(content, content2) {
+		t.Error("gzip output should be deterministic")
+	}
+}
@@ -0,0 +1,165 @@
+package alteration
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+// OptimizePng is an alteration that re-encodes a PNG image
+// with the best compression. It is written in go, no program is needed.
+// The compression is lossless: the image is encoded with a palette or in
+// grayscale when it does not lose any color. The metadata are not kept.
+// If the result is not smaller, the original content is returned.
+type OptimizePng struct{}
+
+// NewOptimizePng creates a new OptimizePng alteration.
+func NewOptimizePng() OptimizePng {
+	return OptimizePng{}
+}
+
+// Alter re-encodes the PNG image and returns the smallest content.
+func (op OptimizePng) Alter(r resource.Resource) (resource.Resource, error) {
+	c, err := r.Dump()
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	img, err := png.Decode(bytes.NewReader(c))
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	best := c
+
+	for _, candidate := range []image.Image{img, paletted(img), gray(img)} {
+		if candidate == nil {
+			continue
+		}
+
+		buf := bytes.NewBuffer(nil)
+		if err = encoder.Encode(buf, candidate); err != nil {
+			return &resource.Empty{}, err
+		}
+
+		if buf.Len() < len(best) {
+			best = buf.Bytes()
+		}
+	}
+
+	return resource.NewBytes(best), nil
+}
+
+// paletted returns `img` as an image.Paletted if it has at most 256 colors
+// that can be represented with 8 bits per channel. Otherwise it returns nil.
+func paletted(img image.Image) image.Image {
+	if _, ok := img.(*image.Paletted); ok {
+		return nil
+	}
+
+	b := img.Bounds()
+	palette := color.Palette{}
+	indexes := map[color.NRGBA]uint8{}
+	dst := image.NewPaletted(b, nil)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			px := img.At(x, y)
+			c := color.NRGBAModel.Convert(px).(color.NRGBA)
+			if !sameColor(px, c) {
+				return nil
+			}
+			i, ok := indexes[c]
+			if !ok {
+				if len(palette) == 256 {
+					return nil
+				}
+				i = uint8(len(palette))
+				indexes[c] = i
+				palette = append(palette, c)
+			}
+			dst.SetColorIndex(x, y, i)
+		}
+	}
+
+	dst.Palette = palette
+	return dst
+}
+
+// gray returns `img` as an image.Gray if all its pixels are opaque and gray,
+// with 8 bits of precision. Otherwise it returns nil.
+func gray(img image.Image) image.Image {
+	if _, ok := img.(*image.Gray); ok {
+		return nil
+	}
+
+	b := img.Bounds()
+	dst := image.NewGray(b)
+
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			px := img.At(x, y)
+			c := color.GrayModel.Convert(px).(color.Gray)
+			if !sameColor(px, c) {
+				return nil
+			}
+			dst.SetGray(x, y, c)
+		}
+	}
+
+	return dst
+}
+
+// sameColor checks if `a` and `b` are the same color at full depth,
+// so that 16-bit images are not converted with a loss of precision.
+func sameColor(a, b color.Color) bool {
+	r1, g1, b1, a1 := a.RGBA()
+	r2, g2, b2, a2 := b.RGBA()
+	return r1 == r2 && g1 == g2 && b1 == b2 && a1 == a2
+}
+
+// OptimizeJpeg is an alteration that re-encodes a JPEG image.
+// It is written in go, no program is needed.
+// Quality is the quality of the encoded image (from 1 to 100).
+// If it is not positive, the quality is 85.
+// The metadata (including the orientation) are not kept.
+// If the result is not smaller, the original content is returned.
+type OptimizeJpeg struct {
+	Quality int
+}
+
+// NewOptimizeJpeg creates a new OptimizeJpeg alteration.
+func NewOptimizeJpeg(quality int) OptimizeJpeg {
+	return OptimizeJpeg{
+		Quality: quality,
+	}
+}
+
+// Alter re-encodes the JPEG image and returns the smallest content.
+func (oj OptimizeJpeg) Alter(r resource.Resource) (resource.Resource, error) {
+	c, err := r.Dump()
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(c))
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = jpeg.Encode(buf, img, &jpeg.Options{Quality: jpegQuality(oj.Quality)}); err != nil {
+		return &resource.Empty{}, err
+	}
+
+	if buf.Len() >= len(c) {
+		return resource.NewBytes(c), nil
+	}
+
+	return resource.NewBytes(buf.Bytes()), nil
+}
@@ -0,0 +1,153 @@
+package alteration
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+func TestOptimizePng(t *testing.T) {
+	img := testImage(64, 64)
+
+	buf := bytes.NewBuffer(nil)
+	encoder := png.Encoder{CompressionLevel: png.NoCompression}
+	encoder.Encode(buf, img)
+
+	r, err := NewOptimizePng().Alter(resource.NewBytes(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := r.Dump()
+	if len(c) >= buf.Len() {
+		t.Error("the optimized png should be smaller")
+	}
+
+	optimized, err := png.Decode(bytes.NewReader(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := optimized.(*image.Paletted); !ok {
+		t.Error("an image with 2 colors should use a palette")
+	}
+
+	for _, p := range []image.Point{{0, 0}, {63, 63}} {
+		if color.NRGBAModel.Convert(optimized.At(p.X, p.Y)) != img.At(p.X, p.Y) {
+			t.Error("the optimization should be lossless")
+		}
+	}
+}
+
+func TestOptimizePngGray(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 300, 1))
+	for x := 0; x < 300; x++ {
+		img.SetNRGBA(x, 0, color.NRGBA{R: uint8(x), G: uint8(x), B: uint8(x), A: 255})
+	}
+
+	if _, ok := gray(img).(*image.Gray); !ok {
+		t.Error("an opaque gray image should be converted to grayscale")
+	}
+
+	colors := image.NewNRGBA(image.Rect(0, 0, 300, 1))
+	for x := 0; x < 300; x++ {
+		colors.SetNRGBA(x, 0, color.NRGBA{R: uint8(x), G: uint8(x / 256), A: 255})
+	}
+	if paletted(colors) != nil {
+		t.Error("an image with more than 256 colors can not use a palette")
+	}
+
+	img.SetNRGBA(0, 0, color.NRGBA{R: 1, A: 255})
+	if gray(img) != nil {
+		t.Error("an image with colors can not be converted to grayscale")
+	}
+}
+
+func TestOptimizePng16(t *testing.T) {
+	img := image.NewGray16(image.Rect(0, 0, 64, 64))
+	for y := 0; y < 64; y++ {
+		for x := 0; x < 64; x++ {
+			img.SetGray16(x, y, color.Gray16{Y: uint16(x%2)*0x1234 + 0x0101})
+		}
+	}
+
+	if gray(img) != nil || paletted(img) != nil {
+		t.Error("a 16-bit image should not be converted to 8 bits")
+	}
+
+	buf := bytes.NewBuffer(nil)
+	encoder := png.Encoder{CompressionLevel: png.NoCompression}
+	encoder.Encode(buf, img)
+
+	r, err := NewOptimizePng().Alter(resource.NewBytes(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := r.Dump()
+	optimized, err := png.Decode(bytes.NewReader(c))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := optimized.(*image.Gray16); !ok {
+		t.Errorf("a 16-bit image should stay a 16-bit image instead of %T", optimized)
+	}
+
+	for _, p := range []image.Point{{0, 0}, {1, 0}, {63, 63}} {
+		if !sameColor(optimized.At(p.X, p.Y), img.At(p.X, p.Y)) {
+			t.Error("the optimization should keep the 16-bit precision")
+		}
+	}
+
+	img8 := image.NewGray16(image.Rect(0, 0, 2, 1))
+	img8.SetGray16(1, 0, color.Gray16{Y: 0x4242})
+	if _, ok := gray(img8).(*image.Gray); !ok {
+		t.Error("a 16-bit image with 8-bit values should be converted to grayscale")
+	}
+}
+
+func TestOptimizeKeepsOriginal(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	png.Encode(buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+
+	r, err := NewOptimizePng().Alter(resource.NewBytes(buf.Bytes()))
+	c, _ := r.Dump()
+	if err != nil || len(c) > buf.Len() {
+		t.Error("the optimized png should never be bigger than the original")
+	}
+
+	buf.Reset()
+	jpeg.Encode(buf, testImage(32, 32), &jpeg.Options{Quality: 50})
+
+	r, err = NewOptimizeJpeg(95).Alter(resource.NewBytes(buf.Bytes()))
+	c, _ = r.Dump()
+	if err != nil || !bytes.Equal(c, buf.Bytes()) {
+		t.Error("the original jpeg should be kept if the result is bigger")
+	}
+
+	r, err = NewOptimizeJpeg(10).Alter(resource.NewBytes(buf.Bytes()))
+	c, _ = r.Dump()
+	if err != nil || len(c) >= buf.Len() {
+		t.Error("the jpeg should be smaller with a lower quality")
+	}
+
+	buf.Reset()
+	jpeg.Encode(buf, testImage(32, 32), &jpeg.Options{Quality: 100})
+
+	r, err = OptimizeJpeg{}.Alter(resource.NewBytes(buf.Bytes()))
+	c, _ = r.Dump()
+	expected, _ := NewOptimizeJpeg(85).Alter(resource.NewBytes(buf.Bytes()))
+	e, _ := expected.Dump()
+	if err != nil || !bytes.Equal(c, e) {
+		t.Error("an OptimizeJpeg without Quality should use a quality of 85")
+	}
+
+	if _, err = NewOptimizePng().Alter(resource.NewString("not a png")); err == nil {
+		t.Error("invalid images should return an error")
+	}
+
+}
@@ -0,0 +1,142 @@
+package alteration
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+// Resize is an alteration that resizes a PNG, JPEG or GIF image.
+// It is written in go, no program is needed.
+// Width is the width of the resized image. The height keeps the ratio of the image.
+// Images that are not wider than Width are not enlarged, their content is returned as it is.
+// Quality is the quality of the JPEG images (from 1 to 100).
+// If it is not positive, the quality is 85.
+// The resized image is encoded in the format of the original image.
+type Resize struct {
+	Width   int
+	Quality int
+}
+
+// NewResize creates a new Resize alteration.
+// The quality of JPEG images is 85.
+func NewResize(width int) Resize {
+	return Resize{
+		Width:   width,
+		Quality: defaultJpegQuality,
+	}
+}
+
+// defaultJpegQuality is the quality of the JPEG images encoded
+// by the alterations without a positive Quality.
+const defaultJpegQuality = 85
+
+// jpegQuality returns `quality`, or defaultJpegQuality if it is not positive.
+func jpegQuality(quality int) int {
+	if quality <= 0 {
+		return defaultJpegQuality
+	}
+	return quality
+}
+
+// Alter resizes the image and returns the resized one.
+// Each pixel of the resized image is the average
+// of the pixels of the original image it covers.
+func (rs Resize) Alter(r resource.Resource) (resource.Resource, error) {
+	c, err := r.Dump()
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	if rs.Width <= 0 {
+		return &resource.Empty{}, errors.New("the width of a Resize should be positive")
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(c))
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	b := img.Bounds()
+	if b.Dx() <= rs.Width {
+		return resource.NewBytes(c), nil
+	}
+
+	height := (b.Dy()*rs.Width + b.Dx()/2) / b.Dx()
+	if height < 1 {
+		height = 1
+	}
+
+	resized := resize(img, rs.Width, height)
+
+	buf := bytes.NewBuffer(nil)
+
+	switch format {
+	case "jpeg":
+		err = jpeg.Encode(buf, resized, &jpeg.Options{Quality: jpegQuality(rs.Quality)})
+	case "gif":
+		err = gif.Encode(buf, resized, nil)
+	default:
+		err = png.Encode(buf, resized)
+	}
+	if err != nil {
+		return &resource.Empty{}, err
+	}
+
+	return resource.NewBytes(buf.Bytes()), nil
+}
+
+// resize scales down `img` to `width`x`height` with an area average.
+func resize(img image.Image, width, height int) *image.NRGBA {
+	b := img.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := b.Min.Y + y*b.Dy()/height
+		y1 := b.Min.Y + (y+1)*b.Dy()/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+
+		for x := 0; x < width; x++ {
+			x0 := b.Min.X + x*b.Dx()/width
+			x1 := b.Min.X + (x+1)*b.Dx()/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(img.At(sx, sy)).(color.NRGBA64)
+					// the colors are weighted by the alpha channel,
+					// so transparent pixels do not darken the result
+					r += uint64(c.R) * uint64(c.A)
+					g += uint64(c.G) * uint64(c.A)
+					bl += uint64(c.B) * uint64(c.A)
+					a += uint64(c.A)
+					n++
+				}
+			}
+
+			if a == 0 {
+				continue
+			}
+
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r / a >> 8),
+				G: uint8(g / a >> 8),
+				B: uint8(bl / a >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return dst
+}
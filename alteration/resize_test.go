@@ -0,0 +1,99 @@
+package alteration
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+func testImage(width, height int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				img.SetNRGBA(x, y, color.NRGBA{R: 255, A: 255})
+			} else {
+				img.SetNRGBA(x, y, color.NRGBA{B: 255, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func TestResizePNG(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	png.Encode(buf, testImage(40, 20))
+
+	r, err := NewResize(10).Alter(resource.NewBytes(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := r.Dump()
+	img, format, err := image.Decode(bytes.NewReader(c))
+	if err != nil || format != "png" {
+		t.Fatal("the resized image should be a png: ", err)
+	}
+	if img.Bounds().Dx() != 10 || img.Bounds().Dy() != 5 {
+		t.Error("wrong size of the resized image: ", img.Bounds())
+	}
+
+	red := color.NRGBAModel.Convert(img.At(1, 1)).(color.NRGBA)
+	blue := color.NRGBAModel.Convert(img.At(8, 3)).(color.NRGBA)
+	if red != (color.NRGBA{R: 255, A: 255}) || blue != (color.NRGBA{B: 255, A: 255}) {
+		t.Error("the colors of the image should be kept: ", red, blue)
+	}
+}
+
+func TestResizeJPEG(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	jpeg.Encode(buf, testImage(64, 32), nil)
+
+	r, err := NewResize(16).Alter(resource.NewBytes(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := r.Dump()
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(c))
+	if err != nil || format != "jpeg" || cfg.Width != 16 || cfg.Height != 8 {
+		t.Error("wrong resized jpeg: ", format, cfg, err)
+	}
+}
+
+func TestResizeZeroQuality(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	jpeg.Encode(buf, testImage(64, 32), &jpeg.Options{Quality: 100})
+
+	r, err := Resize{Width: 16}.Alter(resource.NewBytes(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := NewResize(16).Alter(resource.NewBytes(buf.Bytes()))
+
+	c, _ := r.Dump()
+	e, _ := expected.Dump()
+	if !bytes.Equal(c, e) {
+		t.Error("a Resize without Quality should use the default quality")
+	}
+}
+
+func TestResizeSmallImage(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	png.Encode(buf, testImage(8, 8))
+
+	r, err := NewResize(10).Alter(resource.NewBytes(buf.Bytes()))
+	c, _ := r.Dump()
+	if err != nil || !bytes.Equal(c, buf.Bytes()) {
+		t.Error("images smaller than the width should not be changed")
+	}
+
+	if _, err = NewResize(10).Alter(resource.NewString("not an image")); err == nil {
+		t.Error("invalid images should return an error")
+	}
+}
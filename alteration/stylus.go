@@ -15,12 +15,23 @@ func NewStylus(bin string) Stylus {
 	}
 }
 
+// Rename replaces the .styl extension by .css.
+// It implements the resource.Renamer interface,
+// so the outputs of an AssetPack are named after the compiled files.
+func (ts Stylus) Rename(name string) string {
+	return resource.ReplaceExt(name, ".styl", ".css")
+}
+
 // Alter runs the stylus compiler on a resource and returns a compiled one.
+// The imports are resolved relative to the source file of the resource
+// (see resource.MetadataOf), even if it has been altered before.
 func (ts Stylus) Alter(r resource.Resource) (resource.Resource, error) {
-	switch r := r.(type) {
-	case *resource.File:
-		return ExecCommand(ts.Bin, "-o", TmpOutputFile{Suffix: ".css"}, r.Path)
-	default:
-		return ExecCommand(ts.Bin, "-o", TmpOutputFile{Suffix: ".css"}, TmpInputFile{Resource: r, Suffix: ".styl"})
+	input, dir := sourceInput(r, ".styl")
+
+	args := []interface{}{"-o", TmpOutputFile{Suffix: ".css"}}
+	if dir != "" {
+		args = append(args, "--include", dir)
 	}
+
+	return ExecCommand(ts.Bin, append(args, input)...)
 }
@@ -3,6 +3,7 @@ package alteration
 import (
 	"bytes"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/sarulabs/statix/resource"
@@ -74,3 +75,79 @@ func TestStylusFile(t *testing.T) {
 		t.Error("content dumped is not correct", string(content))
 	}
 }
+
+// prefixAlteration is an alteration adding a prefix to the content of a resource.
+type prefixAlteration string
+
+func (pa prefixAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	c, err := r.Dump()
+	return resource.NewBytes(append([]byte(pa), c...)), err
+}
+
+func TestStylusAlteredFile(t *testing.T) {
+	bin := os.Getenv("STATIX_TEST_STYLUS_BIN")
+
+	if bin == "" {
+		t.Skip("STATIX_TEST_STYLUS_BIN is not set")
+	}
+
+	s := resource.NewAlteredResource(
+		resource.NewFile("./testFiles/test-main.styl"),
+		prefixAlteration("body\n  color blue\n"),
+		NewStylus(bin),
+	)
+
+	content, err := s.Dump()
+	if err != nil {
+		t.Fatal("could not dump content", err)
+	}
+
+	expected := `body {
+  color: #00f;
+}
+html {
+  color: #f00;
+}
+`
+
+	if !bytes.Equal(content, []byte(expected)) {
+		t.Error("the import should be relative to the source file", string(content))
+	}
+}
+
+func TestSourceInput(t *testing.T) {
+	f := resource.NewFile("./testFiles/test-main.styl")
+	dir := filepath.Dir(f.Path)
+
+	input, inputDir := sourceInput(f, ".styl")
+	if input != f.Path || inputDir != dir {
+		t.Error("the source file should be used directly: ", input, inputDir)
+	}
+
+	altered, _ := resource.ApplyAlteration(prefixAlteration("body\n"), f)
+
+	input, inputDir = sourceInput(altered, ".styl")
+	tmp, ok := input.(TmpInputFile)
+	if !ok || tmp.Suffix != ".styl" || inputDir != dir {
+		t.Fatal("an altered file should be written in a temporary file and keep its source directory: ", input, inputDir)
+	}
+	if c, _ := tmp.Resource.Dump(); !bytes.HasPrefix(c, []byte("body\n @import")) {
+		t.Error("the temporary file should contain the altered content: ", string(c))
+	}
+
+	input, inputDir = sourceInput(resource.NewString("html"), ".styl")
+	if _, ok := input.(TmpInputFile); !ok || inputDir != "" {
+		t.Error("a resource without source should be written in a temporary file: ", input, inputDir)
+	}
+}
+
+func TestStylusRename(t *testing.T) {
+	a := NewStylus("stylus")
+
+	if name := a.Rename("/css/main.styl"); name != "/css/main.css" {
+		t.Error("main.styl should be renamed main.css instead of ", name)
+	}
+	if name := a.Rename("/css/main.css"); name != "/css/main.css" {
+		t.Error("main.css should not be renamed instead of ", name)
+	}
+}
@@ -15,13 +15,18 @@ func NewTypeScript(bin string) TypeScript {
 	}
 }
 
+// Rename replaces the .ts extension by .js.
+// It implements the resource.Renamer interface,
+// so the outputs of an AssetPack are named after the compiled files.
+func (ts TypeScript) Rename(name string) string {
+	return resource.ReplaceExt(name, ".ts", ".js")
+}
+
 // Alter runs the typescript compiler on a resource
-// and returns a compiled one.
+// and returns a compiled one. If the resource has the content
+// of its source file (see resource.MetadataOf), the compiler reads this file.
 func (ts TypeScript) Alter(r resource.Resource) (resource.Resource, error) {
-	switch r := r.(type) {
-	case *resource.File:
-		return ExecCommand(ts.Bin, "--out", TmpOutputFile{}, r.Path)
-	default:
-		return ExecCommand(ts.Bin, "--out", TmpOutputFile{}, TmpInputFile{Resource: r, Suffix: ".ts"})
-	}
+	input, _ := sourceInput(r, ".ts")
+
+	return ExecCommand(ts.Bin, "--out", TmpOutputFile{}, input)
 }
@@ -76,3 +76,9 @@ func TestTypeScriptFile(t *testing.T) {
 		t.Error("content dumped is not correct", string(content))
 	}
 }
+
+func TestTypeScriptRename(t *testing.T) {
+	if name := NewTypeScript("tsc").Rename("/js/app.ts"); name != "/js/app.js" {
+		t.Error("app.ts should be renamed app.js instead of ", name)
+	}
+}
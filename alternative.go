@@ -0,0 +1,104 @@
+package statix
+
+import (
+	"errors"
+	"mime"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+// Alternative is an other format of the files of an AssetPack,
+// for example a WebP image next to a PNG or JPEG image.
+// It is dumped for each output matching Alternative.Pattern, with
+// Alternative.Alteration applied to the content of the output.
+// The name of the alternative is the name of the output followed
+// by Alternative.Extension, for example `photo.jpg.webp`.
+// It is fingerprinted separately, so its file is `photo.jpg.{MD5}.webp`.
+// ContentType is the media type of the alternative (for example `image/webp`).
+// It is used in the Manifest and by Manager.Sources. If it is empty,
+// it is guessed from the Extension with mime.TypeByExtension.
+// Like a compressed sibling, an alternative is only written
+// if it is smaller than the output. Otherwise, the alternative written
+// by a previous dump is unlinked if the dumper implements the Unlinker interface.
+type Alternative struct {
+	Extension   string
+	ContentType string
+	Pattern     Pattern
+	Alteration  resource.Alteration
+}
+
+// Alternate applies the Alternative.Alteration to `content`.
+// The second returned value is false if the result is not
+// smaller than `content`. In this case, the alternative should not be written.
+func (a Alternative) Alternate(content []byte) ([]byte, bool, error) {
+	r, err := a.Alteration.Alter(resource.NewBytes(content))
+	if err != nil {
+		return []byte{}, false, err
+	}
+
+	c, err := r.Dump()
+	if err != nil {
+		return []byte{}, false, err
+	}
+
+	return c, len(c) < len(content), nil
+}
+
+// contentType returns Alternative.ContentType
+// or the media type matching Alternative.Extension.
+func (a Alternative) contentType() string {
+	if a.ContentType != "" {
+		return a.ContentType
+	}
+	return mime.TypeByExtension(a.Extension)
+}
+
+// Source is a format of an image, like in the <source> elements of a <picture>.
+type Source struct {
+	Type string
+	URL  string
+}
+
+// Sources returns the alternatives of a file of an AssetPack that have been dumped,
+// in the order of AssetPack.Alternatives. The `path` is the path of the file
+// inside the output directory, like in Manager.URL.
+// They can be used to write the <source> elements of a <picture>.
+// In development mode, the alternatives are not served, so no Source is returned.
+func (m Manager) Sources(assetName string, path string) []Source {
+	sources, _ := m.sources(assetName, path)
+	return sources
+}
+
+func (m Manager) sources(assetName string, path string) ([]Source, error) {
+	ap, ok := m.Assets[assetName].(AssetPack)
+	if !ok {
+		return nil, errors.New("asset `" + assetName + "` is not an AssetPack")
+	}
+
+	if m.Dev {
+		return nil, nil
+	}
+
+	symlink, err := m.Symlink(assetName, path)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := []Source{}
+
+	for _, a := range ap.Alternatives {
+		if !a.Pattern.Match(symlink) {
+			continue
+		}
+
+		// the alternative is not written if it is not smaller than the file
+		url, err := m.URLFromSymlink(symlink + a.Extension)
+		if err != nil {
+			continue
+		}
+
+		sources = append(sources, Source{Type: a.contentType(), URL: url})
+	}
+
+	return sources, nil
+}
@@ -0,0 +1,174 @@
+package statix
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+// TruncateAlteration is an alteration that keeps the first bytes of the content of a resource.
+type TruncateAlteration int
+
+func (ta TruncateAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	c, err := r.Dump()
+	if len(c) > int(ta) {
+		c = c[:ta]
+	}
+	return resource.NewBytes(c), err
+}
+
+func TestAssetPackAlternatives(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Manifest = "manifest.json"
+	m.Assets["pack"] = AssetPack{
+		Input:  "dirIn",
+		Output: "dirOut",
+		Alternatives: []Alternative{
+			{Extension: ".alt", ContentType: "text/x-alt", Pattern: NewExtensionPattern("ext"), Alteration: TruncateAlteration(3)},
+			{Extension: ".big", ContentType: "text/x-big", Pattern: NewExtensionPattern("ext"), Alteration: SuffixAlteration("-big")},
+		},
+	}
+
+	report, err := m.Dump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Created()) != 4 {
+		t.Error("a1, a2.ext, a2.ext.alt and single should be dumped: ", report.Created())
+	}
+
+	content, err := ioutil.ReadFile("./tests/out/dirOut/subDir/a2.ext.alt")
+	if err != nil || !bytes.Equal(content, []byte("2a-")) {
+		t.Error("the alternative should be created from the filtered content: ", string(content), err)
+	}
+	if _, err = ioutil.ReadFile("./tests/out/dirOut/subDir/a2.ext.big"); err == nil {
+		t.Error("an alternative that is not smaller should not be written")
+	}
+
+	manifest, err := ReadManifest("./tests/out/manifest.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	entry := manifest["dirOut/subDir/a2.ext"]
+	if entry.Alternatives["text/x-alt"] != "dirOut/subDir/a2.ext.0e4db71910a804ae3dcd7f4f79f8867d.alt" || len(entry.Alternatives) != 1 {
+		t.Error("wrong alternatives in the manifest: ", entry.Alternatives)
+	}
+	if _, ok := manifest["dirOut/subDir/a2.ext.alt"]; !ok {
+		t.Error("the alternative should have its own entry in the manifest")
+	}
+
+	sources := m.Sources("pack", "subDir/a2.ext")
+	expected := "http://www.example.com/static/dirOut/subDir/a2.ext.0e4db71910a804ae3dcd7f4f79f8867d.alt"
+	if len(sources) != 1 || sources[0].Type != "text/x-alt" || sources[0].URL != expected {
+		t.Error("wrong sources: ", sources)
+	}
+	if sources = m.Sources("pack", "a1"); len(sources) != 0 {
+		t.Error("a1 does not match the pattern of the alternatives: ", sources)
+	}
+	if sources = m.Sources("single", ""); len(sources) != 0 {
+		t.Error("a SingleAsset has no alternatives: ", sources)
+	}
+
+	m.Dev = true
+	if sources = m.Sources("pack", "subDir/a2.ext"); len(sources) != 0 {
+		t.Error("the alternatives are not served in development mode: ", sources)
+	}
+	m.Dev = false
+
+	// the truncated content is not smaller anymore
+	ioutil.WriteFile("./tests/in/dirIn/subDir/a2.ext", []byte("a2"), 0777)
+
+	if _, err = m.Dump(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Lstat("./tests/out/dirOut/subDir/a2.ext.alt"); err == nil {
+		t.Error("the alternative of the previous dump should be removed")
+	}
+	if sources = m.Sources("pack", "subDir/a2.ext"); len(sources) != 0 {
+		t.Error("the alternative of the previous dump should not be listed: ", sources)
+	}
+}
+
+// lazyAlteration returns a resource.AlteredResource applying
+// its Alteration each time the resource is dumped.
+type lazyAlteration struct {
+	resource.Alteration
+}
+
+func (la lazyAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	return resource.NewAlteredResource(r, la.Alteration), nil
+}
+
+func TestAssetPackAlternativesAlterationCalls(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	calls := 0
+
+	m := getManagerTest()
+	m.Filters = []Filter{
+		{Pattern: NewExtensionPattern("ext"), Alteration: lazyAlteration{countingAlteration{calls: &calls}}},
+	}
+	m.Assets = map[string]Asset{
+		"pack": AssetPack{
+			Input:  "dirIn",
+			Output: "dirOut",
+			Alternatives: []Alternative{
+				{Extension: ".alt", ContentType: "text/x-alt", Pattern: NewExtensionPattern("ext"), Alteration: TruncateAlteration(3)},
+				{Extension: ".big", ContentType: "text/x-big", Pattern: NewExtensionPattern("ext"), Alteration: SuffixAlteration("-big")},
+			},
+		},
+	}
+
+	if _, err := m.Dump(); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 1 {
+		t.Error("the filter should be applied once for the file and its alternatives instead of ", calls)
+	}
+
+	content, err := ioutil.ReadFile("./tests/out/dirOut/subDir/a2.ext.alt")
+	if err != nil || !bytes.Equal(content, []byte("pac")) {
+		t.Error("the alternative should be created from the dumped content: ", string(content), err)
+	}
+}
+
+func TestManifestNegotiate(t *testing.T) {
+	manifest := Manifest{
+		"photo.jpg": ManifestEntry{
+			File: "photo.1.jpg",
+			Alternatives: map[string]string{
+				"image/webp": "photo.jpg.2.webp",
+				"image/avif": "photo.jpg.3.avif",
+			},
+		},
+	}
+
+	tests := map[string]string{
+		"":                                   "photo.1.jpg",
+		"*/*":                                "photo.1.jpg",
+		"image/webp,*/*":                     "photo.jpg.2.webp",
+		"image/avif,image/webp,*/*;q=0.8":    "photo.jpg.3.avif",
+		"image/avif;q=0, image/webp;q=0.9":   "photo.jpg.2.webp",
+		"image/avif;q=0.0, image/webp;q=0.0": "photo.1.jpg",
+	}
+
+	for accept, expected := range tests {
+		if file := manifest.Negotiate("photo.jpg", accept); file != expected {
+			t.Error("`", accept, "` should give ", expected, " instead of ", file)
+		}
+	}
+
+	if file := manifest.Negotiate("missing.jpg", "image/webp"); file != "" {
+		t.Error("a missing symlink should give an empty string: ", file)
+	}
+}
@@ -0,0 +1,199 @@
+package statix
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+// ArchiveFormat is the format of the archive written by an ArchiveDumper.
+type ArchiveFormat int
+
+const (
+	// TarGz is a tar archive compressed with gzip.
+	TarGz ArchiveFormat = iota
+	// Zip is a zip archive.
+	Zip
+)
+
+// ArchiveFormatFromFilename returns the ArchiveFormat matching
+// the extension of `filename` (.tar.gz, .tgz or .zip).
+func ArchiveFormatFromFilename(filename string) (ArchiveFormat, error) {
+	switch {
+	case strings.HasSuffix(filename, ".tar.gz"), strings.HasSuffix(filename, ".tgz"):
+		return TarGz, nil
+	case strings.HasSuffix(filename, ".zip"):
+		return Zip, nil
+	}
+	return TarGz, fmt.Errorf("unknown archive format for `%s`", filename)
+}
+
+// ArchiveDumper is a MemoryDumper that can write the dumped files in an archive.
+// The archive is reproducible: the entries are sorted by name,
+// and they all have the same modification time (ArchiveDumper.ModTime).
+// If ArchiveDumper.Aliases is true, the aliases are written as symlink entries.
+// Otherwise only the files are written and a Manifest should be used
+// to find the files from the names of the assets.
+type ArchiveDumper struct {
+	*MemoryDumper
+	Format  ArchiveFormat
+	Aliases bool
+	ModTime time.Time
+}
+
+// NewArchiveDumper creates an ArchiveDumper that can dump the files located in `root`.
+// The aliases are written in the archive.
+// The modification time of the entries is 1980-01-01, the oldest date a zip archive can store.
+func NewArchiveDumper(root string, format ArchiveFormat) *ArchiveDumper {
+	return &ArchiveDumper{
+		MemoryDumper: NewMemoryDumper(root),
+		Format:       format,
+		Aliases:      true,
+		ModTime:      time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// archiveEntry is a file or an alias of an archive.
+type archiveEntry struct {
+	name   string
+	data   []byte
+	target string
+}
+
+// entries returns the files and the aliases that should be written
+// in the archive, sorted by name. The target of an alias is relative to its directory.
+func (ad *ArchiveDumper) entries() []archiveEntry {
+	ad.mu.RLock()
+	defer ad.mu.RUnlock()
+
+	entries := []archiveEntry{}
+
+	for name, data := range ad.files {
+		entries = append(entries, archiveEntry{name: name, data: data})
+	}
+
+	if ad.Aliases {
+		for alias, target := range ad.aliases {
+			entries = append(entries, archiveEntry{name: alias, target: relativeTarget(alias, target)})
+		}
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+
+	return entries
+}
+
+// relativeTarget returns the path of `target` relative to the directory of `alias`.
+// Both paths are slash separated and relative to the same root.
+func relativeTarget(alias, target string) string {
+	dir := strings.Split(path.Dir(alias), "/")
+	if path.Dir(alias) == "." {
+		dir = nil
+	}
+	parts := strings.Split(target, "/")
+
+	i := 0
+	for i < len(dir) && i < len(parts)-1 && dir[i] == parts[i] {
+		i++
+	}
+
+	rel := []string{}
+	for j := i; j < len(dir); j++ {
+		rel = append(rel, "..")
+	}
+
+	return strings.Join(append(rel, parts[i:]...), "/")
+}
+
+// Write writes the archive in `w`.
+func (ad *ArchiveDumper) Write(w io.Writer) error {
+	if ad.Format == Zip {
+		return ad.writeZip(w)
+	}
+	return ad.writeTarGz(w)
+}
+
+// WriteFile writes the archive in the file named `filename`.
+// The file is replaced atomically with helpers.WriteFile.
+func (ad *ArchiveDumper) WriteFile(filename string) error {
+	buf := bytes.NewBuffer(nil)
+
+	err := ad.Write(buf)
+	if err != nil {
+		return err
+	}
+
+	return helpers.WriteFile(filename, buf.Bytes(), 0644)
+}
+
+func (ad *ArchiveDumper) writeTarGz(w io.Writer) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	for _, e := range ad.entries() {
+		h := &tar.Header{
+			Name:    e.name,
+			Mode:    0644,
+			ModTime: ad.ModTime,
+		}
+		if e.target != "" {
+			h.Typeflag = tar.TypeSymlink
+			h.Linkname = e.target
+			h.Mode = 0777
+		} else {
+			h.Typeflag = tar.TypeReg
+			h.Size = int64(len(e.data))
+		}
+
+		if err := tw.WriteHeader(h); err != nil {
+			return err
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+func (ad *ArchiveDumper) writeZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+
+	for _, e := range ad.entries() {
+		h := &zip.FileHeader{
+			Name:     e.name,
+			Method:   zip.Deflate,
+			Modified: ad.ModTime,
+		}
+		data := e.data
+		if e.target != "" {
+			h.SetMode(os.ModeSymlink | 0777)
+			data = []byte(e.target)
+		} else {
+			h.SetMode(0644)
+		}
+
+		f, err := zw.CreateHeader(h)
+		if err != nil {
+			return err
+		}
+		if _, err = f.Write(data); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
@@ -0,0 +1,120 @@
+package statix
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func dumpArchiveTest(t *testing.T, format ArchiveFormat) *ArchiveDumper {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	output, _ := filepath.Abs("./tests/out")
+	ad := NewArchiveDumper(output, format)
+
+	m := getManagerTest()
+	m.Dumper = ad
+
+	if _, err := m.Dump(); err != nil {
+		t.Error(err)
+	}
+
+	return ad
+}
+
+func TestArchiveDumperTarGz(t *testing.T) {
+	ad := dumpArchiveTest(t, TarGz)
+
+	buf1 := bytes.NewBuffer(nil)
+	buf2 := bytes.NewBuffer(nil)
+	ad.Write(buf1)
+	dumpArchiveTest(t, TarGz).Write(buf2)
+
+	if !bytes.Equal(buf1.Bytes(), buf2.Bytes()) {
+		t.Error("archives of the same assets should be identical")
+	}
+
+	gr, err := gzip.NewReader(buf1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gr)
+
+	expected := []struct {
+		name     string
+		linkname string
+		content  string
+	}{
+		{"dirOut/a1", "a1.df54fa5f220b244f5ed919c871fe56f0", ""},
+		{"dirOut/a1.df54fa5f220b244f5ed919c871fe56f0", "", "pack-a1"},
+		{"dirOut/subDir/a2.4ee925ce5ea7f2ce1d0fe37f273dff23.ext", "", "2a-kcap"},
+		{"dirOut/subDir/a2.ext", "a2.4ee925ce5ea7f2ce1d0fe37f273dff23.ext", ""},
+		{"single.b34c31dcb721861cd51bfa6f3d850524.ext", "", "elgnis"},
+		{"single.ext", "single.b34c31dcb721861cd51bfa6f3d850524.ext", ""},
+	}
+
+	for _, e := range expected {
+		h, err := tr.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, _ := ioutil.ReadAll(tr)
+		if h.Name != e.name || h.Linkname != e.linkname || string(c) != e.content {
+			t.Error("wrong entry: ", h.Name, h.Linkname, string(c))
+		}
+		if !h.ModTime.Equal(ad.ModTime) {
+			t.Error("wrong modification time: ", h.ModTime)
+		}
+	}
+}
+
+func TestArchiveDumperZip(t *testing.T) {
+	ad := dumpArchiveTest(t, Zip)
+	ad.Aliases = false
+
+	buf := bytes.NewBuffer(nil)
+	if err := ad.Write(buf); err != nil {
+		t.Fatal(err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{}
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if len(names) != 3 || names[0] != "dirOut/a1.df54fa5f220b244f5ed919c871fe56f0" {
+		t.Error("wrong zip entries: ", names)
+	}
+
+	rc, _ := zr.File[0].Open()
+	c, _ := io.ReadAll(rc)
+	rc.Close()
+	if string(c) != "pack-a1" {
+		t.Error("wrong zip content: ", string(c))
+	}
+}
+
+func TestRelativeTarget(t *testing.T) {
+	tests := [][3]string{
+		{"app.js", "app.1.js", "app.1.js"},
+		{"js/app.js", "js/app.1.js", "app.1.js"},
+		{"js/app.js", "files/app.1.js", "../files/app.1.js"},
+		{"app.js", "js/app.1.js", "js/app.1.js"},
+	}
+	for _, test := range tests {
+		if rel := relativeTarget(test[0], test[1]); rel != test[2] {
+			t.Error("wrong relative target for ", test[0], ": ", rel)
+		}
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
@@ -16,7 +18,7 @@ import (
 // Asset is the interface for assets managed by statix Manager.
 type Asset interface {
 	RewritePaths(string, string) Asset
-	Dump([]Filter) error
+	Dump([]Filter, []Compression) (DumpReport, error)
 }
 
 // AssetPack implements the Asset interface. It includes all the assets
@@ -24,24 +26,53 @@ type Asset interface {
 // matching the AssetPack.Pattern are part of the AssetPack.
 // When the asset is dumped with the AssetPack.Dumper, AssetPack.Filters are applied before
 // writing the assets in the AssetPack.Output directory.
+// If AssetPack.FS is defined, AssetPack.Input is a directory of this fs.FS
+// instead of a directory of the host file system.
+// AssetPack.Variants are dumped in addition to the files matching their Pattern.
+// AssetPack.Extensions maps the extensions of the input files to the extensions
+// of their outputs, for example "styl" to "css" (see CompiledExtensions).
+// AssetPack.Alternatives are other formats written next to the outputs matching their Pattern.
 type AssetPack struct {
-	Input       string
-	Output      string
-	Pattern     Pattern
-	Alterations []resource.Alteration
-	Dumper      Dumper
+	Input        string
+	Output       string
+	Pattern      Pattern
+	Alterations  []resource.Alteration
+	Variants     []Variant
+	Alternatives []Alternative
+	Extensions   map[string]string
+	Dumper       Dumper
+	FS           fs.FS
+}
+
+// CompiledExtensions contains the extensions of some languages
+// compiled into css, javascript or html. It can be used as AssetPack.Extensions.
+var CompiledExtensions = map[string]string{
+	"styl": "css",
+	"scss": "css",
+	"ts":   "js",
+	"md":   "html",
 }
 
 // RewritePaths returns a new AssetPack with updated input and output.
 // More precisely, if the AssetPack.Input or AssetPack.Output is relative, it is prefixed
 // by the `input` and `output` parameters.
+// If AssetPack.FS is defined, AssetPack.Input is rewritten with helpers.RewriteFSPath.
+// If AssetPack.Dumper is nil, it is replaced by a FileDumper.
 func (ap AssetPack) RewritePaths(input, output string) Asset {
+	rewrite := helpers.RewritePath
+	if ap.FS != nil {
+		rewrite = helpers.RewriteFSPath
+	}
 	return AssetPack{
-		Input:       helpers.RewritePath(input, ap.Input),
-		Output:      helpers.RewritePath(output, ap.Output),
-		Pattern:     ap.Pattern,
-		Alterations: ap.Alterations,
-		Dumper:      FileDumper{},
+		Input:        rewrite(input, ap.Input),
+		Output:       helpers.RewritePath(output, ap.Output),
+		Pattern:      ap.Pattern,
+		Alterations:  ap.Alterations,
+		Variants:     ap.Variants,
+		Alternatives: ap.Alternatives,
+		Extensions:   ap.Extensions,
+		Dumper:       defaultDumper(ap.Dumper),
+		FS:           ap.FS,
 	}
 }
 
@@ -51,61 +82,255 @@ func (ap AssetPack) RewritePaths(input, output string) Asset {
 // applied before dumping the assets with the AssetPack.Dumper.
 // If some filters are passed in the `filters` parameter, they will be applied just after
 // filters in AssetPack.Filters.
-func (ap AssetPack) Dump(filters []Filter) error {
-	var r resource.Resource
+// The `compressions` matching an output are used to write compressed siblings.
+// The AssetPack.Variants of each file are dumped after the file.
+// The returned DumpReport lists the files handled by the AssetPack.Dumper.
+func (ap AssetPack) Dump(filters []Filter, compressions []Compression) (DumpReport, error) {
+	report := DumpReport{}
 
 	files, err := ap.InputFiles()
 	if err != nil {
-		return err
+		return report, err
 	}
 
 	for _, filename := range files {
-		c, err := ioutil.ReadFile(filename)
+		r, err := ap.DumpFile(filename, filters, compressions)
+		report.Add(r)
 		if err != nil {
-			return err
+			return report, err
 		}
 
-		r = resource.NewBytes(c)
+		for _, v := range ap.Variants {
+			if !v.Pattern.Match(filename) {
+				continue
+			}
 
-		for _, a := range ap.Alterations {
-			r, err = a.Alter(r)
+			r, err = ap.DumpVariant(filename, v, filters, compressions)
+			report.Add(r)
 			if err != nil {
-				return err
+				return report, err
 			}
 		}
+	}
 
-		output, err := ap.OutputFile(filename, "")
-		for _, f := range filters {
-			if f.Pattern.Match(output) {
-				r, err = f.Alteration.Alter(r)
-				if err != nil {
-					return err
-				}
-			}
+	return report, nil
+}
+
+// DumpFile dumps only one file of the AssetPack.
+// The `filename` should be located in AssetPack.Input.
+// AssetPack.Alterations, `filters` and `compressions` are applied
+// the same way they are in AssetPack.Dump.
+// The first file of the returned DumpReport is the output of `filename`.
+// It is followed by its AssetPack.Alternatives and by the extra outputs
+// of the resource returned by the alterations (see resource.MultiResource).
+func (ap AssetPack) DumpFile(filename string, filters []Filter, compressions []Compression) (DumpReport, error) {
+	return ap.dumpFile(filename, nil, filters, compressions)
+}
+
+// DumpVariant dumps the Variant `v` of one file of the AssetPack.
+// The Variant.Alteration is applied after AssetPack.Alterations
+// and before the `filters`. The returned DumpReport is like the one of DumpFile.
+func (ap AssetPack) DumpVariant(filename string, v Variant, filters []Filter, compressions []Compression) (DumpReport, error) {
+	return ap.dumpFile(filename, &v, filters, compressions)
+}
+
+// dumpFile dumps a file of the AssetPack, or its Variant `v` if it is not nil.
+// The alternatives of the output are dumped after it. The extra outputs of the altered resource are dumped after the file.
+// Their symlinks are named after the output of the file, followed by the resource.Extra.Suffix.
+func (ap AssetPack) dumpFile(filename string, v *Variant, filters []Filter, compressions []Compression) (DumpReport, error) {
+	report := DumpReport{}
+
+	input := ap.resource(filename)
+
+	r, extras, err := ap.alter(input, v)
+	if err != nil {
+		return report, err
+	}
+
+	output, err := ap.variantOutputFile(filename, v, "")
+	if err != nil {
+		return report, err
+	}
+
+	md5Output := func(hash string) (string, error) {
+		return ap.variantOutputFile(filename, v, "."+hash)
+	}
+
+	r, err = applyFilters(r, output, filters)
+	if err != nil {
+		return report, err
+	}
+
+	// The alternatives are created from the dumped content. It is kept in memory,
+	// so the alterations and the filters are not applied a second time.
+	var c []byte
+
+	if ap.hasAlternatives(output) {
+		if c, err = r.Dump(); err != nil {
+			return report, err
+		}
+		r = &resource.Bytes{Content: c, Meta: resource.MetadataOf(r)}
+	}
+
+	f, err := dumpResource(ap.Dumper, r, md5Output, output, compressions)
+	if err != nil {
+		return report, err
+	}
+	f.InputSize = resource.MetadataOf(input).Size
+
+	alternatives, err := ap.dumpAlternatives(c, &f, compressions)
+	report.Files = append(append(report.Files, f), alternatives...)
+	if err != nil {
+		return report, err
+	}
+
+	for _, e := range extras {
+		symlink := output + e.Suffix
+
+		md5Output := func(hash string) (string, error) {
+			return helpers.AddFileSuffix(symlink, "."+hash), nil
 		}
 
-		c, err = r.Dump()
+		f, err := dumpFiltered(ap.Dumper, e.Resource, md5Output, symlink, filters, compressions)
 		if err != nil {
-			return err
+			return report, err
 		}
 
-		md5Output, err := ap.OutputFile(filename, "."+helpers.MD5(c))
+		report.Files = append(report.Files, f)
+	}
+
+	return report, nil
+}
+
+// alter applies AssetPack.Alterations to `r`, and then the
+// Alteration of the Variant `v` if it is not nil. It also returns
+// the extra outputs of the resources returned by the alterations.
+func (ap AssetPack) alter(r resource.Resource, v *Variant) (resource.Resource, []resource.Extra, error) {
+	alterations := ap.Alterations
+	if v != nil {
+		alterations = append(append([]resource.Alteration{}, alterations...), v.Alteration)
+	}
+
+	extras := []resource.Extra{}
+
+	for _, a := range alterations {
+		var err error
+		r, err = resource.ApplyAlteration(a, r)
 		if err != nil {
-			return err
+			return r, extras, err
+		}
+		if mr, ok := r.(resource.MultiResource); ok {
+			extras = append(extras, mr.Extras()...)
 		}
+	}
+
+	return r, extras, nil
+}
+
+// rename replaces the extension of `filename` as defined in AssetPack.Extensions.
+// Then it applies the Rename method of the AssetPack.Alterations
+// implementing the resource.Renamer interface.
+func (ap AssetPack) rename(filename string) string {
+	ext := filepath.Ext(filename)
+	if to, ok := ap.Extensions[strings.TrimPrefix(ext, ".")]; ok && ext != "" {
+		filename = resource.ReplaceExt(filename, ext, "."+strings.TrimPrefix(to, "."))
+	}
+
+	for _, a := range ap.Alterations {
+		if r, ok := a.(resource.Renamer); ok {
+			filename = r.Rename(filename)
+		}
+	}
+	return filename
+}
+
+// hasAlternatives checks if at least one of the AssetPack.Alternatives matches `symlink`.
+func (ap AssetPack) hasAlternatives(symlink string) bool {
+	for _, a := range ap.Alternatives {
+		if a.Pattern.Match(symlink) {
+			return true
+		}
+	}
+	return false
+}
 
-		err = ap.Dumper.Dump(md5Output, output, c)
+// dumpAlternatives dumps the AssetPack.Alternatives of the dumped file `f`.
+// The content of `f` is `c`. The alternatives are added
+// to f.Alternatives and their DumpedFiles are returned.
+func (ap AssetPack) dumpAlternatives(c []byte, f *DumpedFile, compressions []Compression) ([]DumpedFile, error) {
+	files := []DumpedFile{}
+
+	for _, a := range ap.Alternatives {
+		if !a.Pattern.Match(f.Symlink) {
+			continue
+		}
+
+		alternative, smaller, err := a.Alternate(c)
 		if err != nil {
-			return err
+			return files, err
+		}
+		if !smaller {
+			// the alternative of a previous dump should not be listed by Manager.Sources
+			if err = unlink(ap.Dumper, f.Symlink+a.Extension); err != nil {
+				return files, err
+			}
+			continue
+		}
+
+		symlink := f.Symlink + a.Extension
+		filename := helpers.AddFileSuffix(symlink, "."+helpers.MD5(alternative))
+
+		af, err := dump(ap.Dumper, filename, symlink, alternative, compressions)
+		if err != nil {
+			return files, err
+		}
+		af.InputSize = int64(len(c))
+
+		files = append(files, af)
+
+		if f.Alternatives == nil {
+			f.Alternatives = map[string]string{}
+		}
+		f.Alternatives[a.contentType()] = filename
+	}
+
+	return files, nil
+}
+
+// dumpFiltered applies the `filters` matching `symlink` to `r`
+// and dumps the result with dumpResource.
+func dumpFiltered(dumper Dumper, r resource.Resource, md5Output func(string) (string, error), symlink string, filters []Filter, compressions []Compression) (DumpedFile, error) {
+	r, err := applyFilters(r, symlink, filters)
+	if err != nil {
+		return DumpedFile{}, err
+	}
+
+	return dumpResource(dumper, r, md5Output, symlink, compressions)
+}
+
+// applyFilters applies the `filters` matching `symlink` to `r`.
+func applyFilters(r resource.Resource, symlink string, filters []Filter) (resource.Resource, error) {
+	for _, f := range filters {
+		if f.Pattern.Match(symlink) {
+			var err error
+			r, err = resource.ApplyAlteration(f.Alteration, r)
+			if err != nil {
+				return r, err
+			}
 		}
 	}
 
-	return nil
+	return r, nil
 }
 
 // InputFiles returns all the files contained in AssetPack.Input
 // that are not directories and that match AssetPack.Pattern.
+// If AssetPack.FS is defined, the returned names are paths inside the fs.FS.
 func (ap AssetPack) InputFiles() ([]string, error) {
+	if ap.FS != nil {
+		return ap.fsInputFiles()
+	}
+
 	var walkError error
 	files := []string{}
 
@@ -130,11 +355,185 @@ func (ap AssetPack) InputFiles() ([]string, error) {
 	return files, walkError
 }
 
+// fsInputFiles is the equivalent of InputFiles when AssetPack.FS is defined.
+func (ap AssetPack) fsInputFiles() ([]string, error) {
+	files := []string{}
+	root := helpers.FSPath(ap.Input)
+
+	info, err := fs.Stat(ap.FS, root)
+	if err != nil || !info.IsDir() {
+		return files, fmt.Errorf("asset input `%s` is not a directory", ap.Input)
+	}
+
+	err = fs.WalkDir(ap.FS, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && ap.Pattern.Match(name) {
+			files = append(files, name)
+		}
+		return nil
+	})
+
+	return files, err
+}
+
+// InputFile returns the name of the input file matching `path`,
+// a path relative to AssetPack.Output like the ones returned by AssetPack.OutputPaths.
+func (ap AssetPack) InputFile(p string) string {
+	if ap.FS != nil {
+		return helpers.FSPath(path.Join(ap.Input, filepath.ToSlash(p)))
+	}
+	return filepath.Join(ap.Input, p)
+}
+
+// resource returns the resource used to read the input file `filename`.
+// Its Name is the path of the file relative to AssetPack.Input (see resource.Metadata).
+func (ap AssetPack) resource(filename string) resource.Resource {
+	if ap.FS != nil {
+		name := strings.TrimPrefix(filename, helpers.FSPath(ap.Input)+"/")
+		return &resource.FSFile{FS: ap.FS, Path: filename, Name: name}
+	}
+	name, err := filepath.Rel(ap.Input, filename)
+	if err != nil {
+		name = filepath.Base(filename)
+	}
+	return &resource.File{Path: filename, Name: filepath.ToSlash(name)}
+}
+
+// OutputPaths returns the paths of the outputs of the AssetPack
+// relative to AssetPack.Output. These are the paths that can be given
+// to Manager.URL to get the url of the files in the AssetPack.
+func (ap AssetPack) OutputPaths() ([]string, error) {
+	paths := []string{}
+
+	files, err := ap.InputFiles()
+	if err != nil {
+		return paths, err
+	}
+
+	output, err := filepath.Abs(ap.Output)
+	if err != nil {
+		return paths, err
+	}
+
+	for _, filename := range files {
+		variants := []*Variant{nil}
+		for i := range ap.Variants {
+			if ap.Variants[i].Pattern.Match(filename) {
+				variants = append(variants, &ap.Variants[i])
+			}
+		}
+
+		for _, v := range variants {
+			out, err := ap.variantOutputFile(filename, v, "")
+			if err != nil {
+				return paths, err
+			}
+
+			path, err := relativePath(output, out)
+			if err != nil {
+				return paths, err
+			}
+
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
+
+// variantOutputFile works like OutputFile, but if `v` is not nil,
+// the Variant.Suffix is added to the name of the output before `suffix`.
+func (ap AssetPack) variantOutputFile(filename string, v *Variant, suffix string) (string, error) {
+	out, err := ap.OutputFile(filename, "")
+	if err != nil {
+		return "", err
+	}
+	if v != nil {
+		out = helpers.AddFileSuffix(out, v.Suffix)
+	}
+	return helpers.AddFileSuffix(out, suffix), nil
+}
+
+// source returns the input file and the Variant (nil for the file itself)
+// that give the output `p`, a path relative to AssetPack.Output.
+// The last returned value is false if no input file gives this output.
+// The input file is first guessed from `p`. If the guess is wrong, because an
+// alteration renamed the output, the outputs of all the input files are compared to `p`.
+func (ap AssetPack) source(p string) (string, *Variant, bool) {
+	target, err := filepath.Abs(filepath.Join(ap.Output, p))
+	if err != nil {
+		return "", nil, false
+	}
+
+	gives := func(filename string, v *Variant) bool {
+		if !ap.Pattern.Match(filename) || (v != nil && !v.Pattern.Match(filename)) {
+			return false
+		}
+		out, err := ap.variantOutputFile(filename, v, "")
+		return err == nil && out == target
+	}
+
+	if filename := ap.InputFile(p); gives(filename, nil) && ap.isFile(filename) {
+		return filename, nil, true
+	}
+
+	for i, v := range ap.Variants {
+		ext := filepath.Ext(p)
+		name := strings.TrimSuffix(p, ext)
+		if v.Suffix == "" || !strings.HasSuffix(name, v.Suffix) {
+			continue
+		}
+
+		filename := ap.InputFile(strings.TrimSuffix(name, v.Suffix) + ext)
+		if gives(filename, &ap.Variants[i]) && ap.isFile(filename) {
+			return filename, &ap.Variants[i], true
+		}
+	}
+
+	files, err := ap.InputFiles()
+	if err != nil {
+		return "", nil, false
+	}
+
+	for _, filename := range files {
+		if gives(filename, nil) {
+			return filename, nil, true
+		}
+		for i := range ap.Variants {
+			if gives(filename, &ap.Variants[i]) {
+				return filename, &ap.Variants[i], true
+			}
+		}
+	}
+
+	return "", nil, false
+}
+
+// isFile checks if the input file `filename` exists and is not a directory.
+func (ap AssetPack) isFile(filename string) bool {
+	var info fs.FileInfo
+	var err error
+
+	if ap.FS != nil {
+		info, err = fs.Stat(ap.FS, filename)
+	} else {
+		info, err = os.Stat(filename)
+	}
+
+	return err == nil && !info.IsDir()
+}
+
 // OutputFile returns the absolute filename of an output based on the filename of the input.
 // It also add a suffix in the basename (for example an md5 hash or a version number).
 // The suffix is inserted just before the file extension and at the end of the filename
 // if no extension was found.
 func (ap AssetPack) OutputFile(filename string, suffix string) (string, error) {
+	if ap.FS != nil {
+		return ap.fsOutputFile(filename, suffix)
+	}
+
 	filename, err := filepath.Abs(filename)
 	if err != nil {
 		return "", err
@@ -154,7 +553,29 @@ func (ap AssetPack) OutputFile(filename string, suffix string) (string, error) {
 	out.WriteByte(os.PathSeparator)
 	out.WriteString(filename[len(input):])
 
-	return helpers.AddFileSuffix(out.String(), suffix), nil
+	return filepath.Abs(helpers.AddFileSuffix(ap.rename(out.String()), suffix))
+}
+
+// fsOutputFile is the equivalent of OutputFile when AssetPack.FS is defined.
+// In this case `filename` is a path inside the fs.FS.
+func (ap AssetPack) fsOutputFile(filename string, suffix string) (string, error) {
+	input := helpers.FSPath(ap.Input)
+	name := helpers.FSPath(filename)
+
+	rel := name
+	if input != "." {
+		if !strings.HasPrefix(name, input+"/") {
+			return "", errors.New(ap.Input + " is not a prefix of " + filename)
+		}
+		rel = name[len(input)+1:]
+	}
+
+	out, err := filepath.Abs(filepath.Join(ap.Output, filepath.FromSlash(rel)))
+	if err != nil {
+		return "", err
+	}
+
+	return helpers.AddFileSuffix(ap.rename(out), suffix), nil
 }
 
 // SingleAsset implements the Asset interface.
@@ -169,46 +590,48 @@ type SingleAsset struct {
 // RewritePaths returns a new SingleAsset with updated input and output.
 // More precisely, if the SingleAsset.Input or SingleAsset.Output path is relative, it is prefixed
 // by the `input` and `output` parameters.
+// If SingleAsset.Dumper is nil, it is replaced by a FileDumper.
 func (sa SingleAsset) RewritePaths(input, output string) Asset {
 	return SingleAsset{
 		Input:  sa.Input.In(input),
 		Output: helpers.RewritePath(output, sa.Output),
-		Dumper: FileDumper{},
+		Dumper: defaultDumper(sa.Dumper),
 	}
 }
 
 // Dump dumps the asset defined in SingleAsset.Input.
 // If some filters are passed in the `filters` parameter, they will be applied before
 // dumping the asset.
-func (sa SingleAsset) Dump(filters []Filter) error {
+// The `compressions` matching the output are used to write compressed siblings.
+// The returned DumpReport contains the file handled by the SingleAsset.Dumper.
+func (sa SingleAsset) Dump(filters []Filter, compressions []Compression) (DumpReport, error) {
 	r := sa.Input
 
+	report := DumpReport{}
+
 	output, err := sa.OutputFile("")
-	for _, f := range filters {
-		if f.Pattern.Match(output) {
-			r, err = f.Alteration.Alter(r)
-			if err != nil {
-				return err
-			}
-		}
+	if err != nil {
+		return report, err
 	}
 
-	c, err := r.Dump()
+	r, err = applyFilters(r, output, filters)
 	if err != nil {
-		return err
+		return report, err
 	}
 
-	md5Output, err := sa.OutputFile("." + helpers.MD5(c))
-	if err != nil {
-		return err
+	md5Output := func(hash string) (string, error) {
+		return sa.OutputFile("." + hash)
 	}
 
-	err = sa.Dumper.Dump(md5Output, output, c)
+	f, err := dumpResource(sa.Dumper, r, md5Output, output, compressions)
 	if err != nil {
-		return err
+		return report, err
 	}
+	f.InputSize = resource.MetadataOf(sa.Input).Size
+
+	report.Files = append(report.Files, f)
 
-	return nil
+	return report, nil
 }
 
 // OutputFile returns the absolute filename of the SingleAsset.Output.
@@ -222,3 +645,130 @@ func (sa SingleAsset) OutputFile(suffix string) (string, error) {
 	}
 	return helpers.AddFileSuffix(out, suffix), nil
 }
+
+// dumpResource dumps the content of `r` with the `dumper`.
+// The name of the file is returned by `md5Output` from the md5 hash of the content.
+// If the `dumper` implements the StreamDumper interface and no compression
+// matches `symlink`, the content is streamed with resource.Open instead of
+// being loaded in memory. The resource is only read once: the hash
+// is computed by the dumper while the content is streamed.
+func dumpResource(dumper Dumper, r resource.Resource, md5Output func(string) (string, error), symlink string, compressions []Compression) (DumpedFile, error) {
+	sd, ok := dumper.(StreamDumper)
+	if !ok || matchCompression(symlink, compressions) {
+		c, err := r.Dump()
+		if err != nil {
+			return DumpedFile{}, err
+		}
+
+		filename, err := md5Output(helpers.MD5(c))
+		if err != nil {
+			return DumpedFile{}, err
+		}
+
+		return dump(dumper, filename, symlink, c, compressions)
+	}
+
+	rc, err := resource.Open(r)
+	if err != nil {
+		return DumpedFile{}, err
+	}
+	defer rc.Close()
+
+	cr := &countingReader{Reader: rc}
+	filename, status, err := sd.DumpReader(md5Output, symlink, cr)
+
+	return DumpedFile{
+		Filename:  filename,
+		Symlink:   symlink,
+		Status:    status,
+		Size:      cr.n,
+		InputSize: -1,
+	}, err
+}
+
+// countingReader is an io.Reader counting the bytes read from Reader.
+type countingReader struct {
+	io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.Reader.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// matchCompression checks if at least one of the `compressions` matches `symlink`.
+func matchCompression(symlink string, compressions []Compression) bool {
+	for _, compression := range compressions {
+		if compression.Pattern.Match(symlink) {
+			return true
+		}
+	}
+	return false
+}
+
+// dump writes `c` in `filename` and creates `symlink` with the `dumper`.
+// The `compressions` matching `symlink` are then used
+// to write the compressed siblings of `filename`. If `filename` is
+// unchanged and a sibling has already been dumped (see Sizer),
+// this compression is not run again.
+func dump(dumper Dumper, filename, symlink string, c []byte, compressions []Compression) (DumpedFile, error) {
+	status, err := dumper.Dump(filename, symlink, c)
+	if err != nil {
+		return DumpedFile{}, err
+	}
+
+	f := DumpedFile{
+		Filename:  filename,
+		Symlink:   symlink,
+		Status:    status,
+		Size:      int64(len(c)),
+		InputSize: -1,
+	}
+
+	for _, compression := range compressions {
+		if !compression.Pattern.Match(symlink) {
+			continue
+		}
+
+		sibling := filename + compression.Extension
+
+		size, exists := int64(-1), false
+		if status == Unchanged {
+			size, exists = dumpedSize(dumper, sibling)
+		}
+
+		if !exists {
+			compressed, smaller, err := compression.Compress(c)
+			if err != nil {
+				return f, err
+			}
+			if !smaller {
+				continue
+			}
+
+			_, err = dumper.Dump(sibling, "", compressed)
+			if err != nil {
+				return f, err
+			}
+
+			size = int64(len(compressed))
+		}
+
+		if f.Compressed == nil {
+			f.Compressed = map[string]string{}
+		}
+		f.Compressed[compression.Extension] = sibling
+
+		if size < 0 {
+			continue
+		}
+		if f.CompressedSizes == nil {
+			f.CompressedSizes = map[string]int64{}
+		}
+		f.CompressedSizes[compression.Extension] = size
+	}
+
+	return f, nil
+}
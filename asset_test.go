@@ -0,0 +1,257 @@
+package statix
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+// CompileAlteration is an alteration that renames .ext files into .out files.
+// It upper cases their content and produces a .map extra output.
+type CompileAlteration struct{}
+
+func (ca CompileAlteration) Rename(name string) string {
+	return resource.ReplaceExt(name, ".ext", ".out")
+}
+
+func (ca CompileAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	c, err := r.Dump()
+	return resource.NewMulti(
+		resource.NewBytes(bytes.ToUpper(c)),
+		resource.Extra{Suffix: ".map", Resource: resource.NewString("map")},
+	), err
+}
+
+func getCompileManagerTest() Manager {
+	m := getManagerTest()
+	m.Filters = append(m.Filters, Filter{
+		Alteration: SuffixAlteration("-filtered"),
+		Pattern:    NewExtensionPattern("out"),
+	})
+	m.Assets["pack"] = AssetPack{
+		Input:       "dirIn",
+		Output:      "dirOut",
+		Alterations: []resource.Alteration{CompileAlteration{}},
+	}
+	return m
+}
+
+// countingAlteration counts the calls to its Alter method.
+type countingAlteration struct {
+	calls *int
+}
+
+func (ca countingAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	*ca.calls++
+	return r, nil
+}
+
+func TestSingleAssetAlterationCalls(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	dumpers := map[string]Dumper{
+		"file":   nil,
+		"plan":   PlanDumper{},
+		"memory": NewMemoryDumper("./tests/out"),
+	}
+
+	for name, dumper := range dumpers {
+		calls := 0
+
+		m := getManagerTest()
+		m.Dumper = dumper
+		m.Assets = map[string]Asset{
+			"app": SingleAsset{
+				Input:  resource.NewAlteredResource(resource.NewString("app;"), countingAlteration{calls: &calls}),
+				Output: "app.js",
+			},
+		}
+
+		if _, err := m.Dump(); err != nil {
+			t.Fatal(name, err)
+		}
+		if calls != 1 {
+			t.Error(name, ": the alteration should be applied once per dump instead of ", calls)
+		}
+	}
+}
+
+func TestSingleAssetGlob(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	ioutil.WriteFile("./tests/in/dirIn/lib.js", []byte("lib;"), 0777)
+	ioutil.WriteFile("./tests/in/dirIn/lib.min.js", []byte("min;"), 0777)
+	ioutil.WriteFile("./tests/in/dirIn/subDir/app.js", []byte("app;"), 0777)
+
+	g := resource.NewGlob("dirIn/subDir/*.js", "dirIn/*.js")
+	g.Excludes = []string{"*.min.js"}
+
+	m := getManagerTest()
+	m.Assets = map[string]Asset{
+		"bundle": SingleAsset{Input: g, Output: "bundle.js"},
+	}
+
+	if _, err := m.Dump(); err != nil {
+		t.Fatal(err)
+	}
+
+	c, _ := ioutil.ReadFile("./tests/out/bundle.js")
+	if string(c) != "app;lib;" {
+		t.Error("the bundle should follow the order of the patterns without the minified file: ", string(c))
+	}
+}
+
+func TestAssetPackRenamedOutputs(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getCompileManagerTest()
+
+	report, err := m.Dump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 5 {
+		t.Error("a1 and a2 should have 2 outputs each: ", len(report.Files))
+	}
+
+	content, err := ioutil.ReadFile("./tests/out/dirOut/subDir/a2.out")
+	if err != nil || !bytes.Equal(content, []byte("PACK-A2-filtered")) {
+		t.Error("the filters should match the renamed output: ", string(content), err)
+	}
+
+	content, err = ioutil.ReadFile("./tests/out/dirOut/subDir/a2.out.map")
+	if err != nil || !bytes.Equal(content, []byte("map")) {
+		t.Error("the extra output should be dumped: ", string(content), err)
+	}
+
+	if url := m.URL("pack", "subDir/a2.out"); url != "http://www.example.com/static/dirOut/subDir/a2.383ff567f552680cc54d0539d8c1ed31.out" {
+		t.Error("wrong url for a renamed output: ", url)
+	}
+
+	paths, err := m.Assets["pack"].RewritePaths(m.Input, m.Output).(AssetPack).OutputPaths()
+	if err != nil || len(paths) != 2 || paths[1] != "subDir/a2.out" {
+		t.Error("the output paths should be renamed: ", paths, err)
+	}
+
+	url, err := m.templateURL("pack", "subDir/a2.out")
+	if err != nil || url != m.URL("pack", "subDir/a2.out") {
+		t.Error("wrong planned url for a renamed output: ", url, err)
+	}
+	if _, err = m.templateURL("pack", "subDir/a2.ext"); err == nil {
+		t.Error("the input name should not give an url")
+	}
+}
+
+func TestDevHandlerRenamedOutputs(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getCompileManagerTest()
+	m.Dev = true
+
+	w := httptest.NewRecorder()
+	m.DevHandler().ServeHTTP(w, httptest.NewRequest("GET", "/static/dirOut/subDir/a2.out", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "PACK-A2" {
+		t.Error("renamed outputs should be compiled by the dev handler: ", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	m.DevHandler().ServeHTTP(w, httptest.NewRequest("GET", "/static/dirOut/a1", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "pack-a1" {
+		t.Error("other files should be served as they are: ", w.Code, w.Body.String())
+	}
+}
+
+func TestAssetPackExtensions(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Filters = []Filter{{Alteration: ReverseAlteration{}, Pattern: NewExtensionPattern("css")}}
+	m.Assets["pack"] = AssetPack{
+		Input:      "dirIn",
+		Output:     "dirOut",
+		Extensions: map[string]string{"ext": ".css"},
+	}
+
+	if _, err := m.Dump(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile("./tests/out/dirOut/subDir/a2.css")
+	if err != nil || !bytes.Equal(content, []byte("2a-kcap")) {
+		t.Error("the filters should match the renamed extension: ", string(content), err)
+	}
+
+	content, err = ioutil.ReadFile("./tests/out/dirOut/a1")
+	if err != nil || !bytes.Equal(content, []byte("pack-a1")) {
+		t.Error("files without extension should not be renamed: ", string(content), err)
+	}
+
+	if url := m.URL("pack", "subDir/a2.css"); url != "http://www.example.com/static/dirOut/subDir/a2.4ee925ce5ea7f2ce1d0fe37f273dff23.css" {
+		t.Error("wrong url for a renamed extension: ", url)
+	}
+
+	ap := m.Assets["pack"].(AssetPack)
+	ap.Extensions = CompiledExtensions
+	if name := ap.rename("/css/main.styl"); name != "/css/main.css" {
+		t.Error("styl should be compiled into css: ", name)
+	}
+}
+
+// MetadataAlteration records the metadata of the resources it receives.
+type MetadataAlteration struct {
+	metadata *[]resource.Metadata
+}
+
+func (ma MetadataAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	*ma.metadata = append(*ma.metadata, resource.MetadataOf(r))
+	return r, nil
+}
+
+func TestAssetPackMetadata(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	metadata := []resource.Metadata{}
+
+	m := getManagerTest()
+	m.Filters = append(m.Filters, Filter{Alteration: MetadataAlteration{&metadata}, Pattern: NewExtensionPattern("ext")})
+	m.Assets = map[string]Asset{
+		"pack": AssetPack{
+			Input:       "dirIn",
+			Output:      "dirOut",
+			Pattern:     NewExtensionPattern("ext"),
+			Alterations: []resource.Alteration{SuffixAlteration("-x"), MetadataAlteration{&metadata}},
+		},
+	}
+
+	if _, err := m.Dump(); err != nil {
+		t.Fatal(err)
+	}
+	if len(metadata) != 2 {
+		t.Fatal("the alteration and the filter should be applied once: ", metadata)
+	}
+
+	for _, md := range metadata {
+		if md.Name != "subDir/a2.ext" || !strings.HasSuffix(md.Source, "dirIn/subDir/a2.ext") || md.Size != 9 {
+			t.Error("the metadata of the input file should flow through the alterations: ", md)
+		}
+	}
+}
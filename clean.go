@@ -0,0 +1,70 @@
+package statix
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// md5FileRegexp matches the names of the files created by a dump.
+// They contain an md5 suffix, optionally followed by extensions.
+var md5FileRegexp = regexp.MustCompile(`\.[0-9a-f]{32}(\.[^/\\]*)?$`)
+
+// Clean removes the files from previous dumps that are not used anymore.
+// It looks for the files with an md5 suffix in Manager.Output.
+// A file is removed if no symlink in Manager.Output points to it,
+// and if it is not the compressed sibling of a file pointed by a symlink.
+// Clean returns the names of the removed files.
+func (m Manager) Clean() ([]string, error) {
+	removed := []string{}
+
+	output, err := filepath.Abs(m.Output)
+	if err != nil {
+		return removed, err
+	}
+
+	targets := map[string]bool{}
+	candidates := []string{}
+
+	err = filepath.Walk(output, func(filename string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			if target, err := filepath.EvalSymlinks(filename); err == nil {
+				targets[target] = true
+			}
+			return nil
+		}
+
+		if info.Mode().IsRegular() && md5FileRegexp.MatchString(filepath.Base(filename)) {
+			candidates = append(candidates, filename)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return removed, err
+	}
+
+	for _, filename := range candidates {
+		real, err := filepath.EvalSymlinks(filename)
+		if err != nil {
+			return removed, err
+		}
+
+		if targets[real] || targets[real[:len(real)-len(filepath.Ext(real))]] {
+			continue
+		}
+
+		err = os.Remove(filename)
+		if err != nil {
+			return removed, err
+		}
+
+		removed = append(removed, filename)
+	}
+
+	return removed, nil
+}
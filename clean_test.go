@@ -0,0 +1,42 @@
+package statix
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManagerClean(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Dump()
+
+	old, _ := filepath.Abs("./tests/out/single.0123456789abcdef0123456789abcdef.ext")
+	ioutil.WriteFile(old, []byte("old"), 0644)
+	ioutil.WriteFile(old+".gz", []byte("old"), 0644)
+
+	kept, _ := filepath.EvalSymlinks("./tests/out/single.ext")
+	ioutil.WriteFile(kept+".gz", []byte("gz"), 0644)
+
+	removed, err := m.Clean()
+	if err != nil {
+		t.Error(err)
+	}
+
+	if len(removed) != 2 {
+		t.Error("old files should be removed instead of ", removed)
+	}
+	if _, err := os.Stat(old); err == nil {
+		t.Error("old file should be removed")
+	}
+	if _, err := os.Stat(kept + ".gz"); err != nil {
+		t.Error("compressed sibling of a used file should be kept")
+	}
+	if _, err := os.Stat("./tests/out/dirOut/a1"); err != nil {
+		t.Error("used files should be kept")
+	}
+}
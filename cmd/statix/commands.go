@@ -0,0 +1,297 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+
+	"github.com/sarulabs/statix"
+	"github.com/sarulabs/statix/helpers"
+)
+
+func runDump(c *cli, args []string) error {
+	flags := flag.NewFlagSet("dump", flag.ContinueOnError)
+	format := flags.String("stats", "", "print the statistics of the dump (table or json)")
+	if err := parseFlags(c, flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *format == "" {
+		report, err := c.manager.Dump()
+		printReport(c, report)
+		return err
+	}
+	if *format != "table" && *format != "json" {
+		return usageError{"-stats should be table or json"}
+	}
+
+	report, stats, err := c.manager.DumpStats()
+	if err != nil {
+		printReport(c, report)
+		return err
+	}
+
+	if *format == "json" {
+		content, err := stats.JSON()
+		if err != nil {
+			return err
+		}
+		_, err = c.stdout.Write(append(content, '\n'))
+		return err
+	}
+
+	return stats.WriteTable(c.stdout)
+}
+
+func runArchive(c *cli, args []string) error {
+	flags := flag.NewFlagSet("archive", flag.ContinueOnError)
+	aliases := flags.Bool("aliases", true, "write the symlinks in the archive")
+	if err := parseFlags(c, flags, args, 1, 1); err != nil {
+		return err
+	}
+
+	format, err := statix.ArchiveFormatFromFilename(flags.Arg(0))
+	if err != nil {
+		return usageError{err.Error()}
+	}
+
+	output, err := filepath.Abs(c.manager.Output)
+	if err != nil {
+		return err
+	}
+
+	ad := statix.NewArchiveDumper(output, format)
+	ad.Aliases = *aliases
+
+	m := c.manager
+	m.Dumper = ad
+
+	report, err := m.Dump()
+	printReport(c, report)
+	if err != nil {
+		return err
+	}
+
+	return ad.WriteFile(flags.Arg(0))
+}
+
+func runPlan(c *cli, args []string) error {
+	if err := parseFlags(c, flag.NewFlagSet("plan", flag.ContinueOnError), args, 0, 0); err != nil {
+		return err
+	}
+
+	m := c.manager
+	m.Dumper = statix.PlanDumper{}
+
+	report, err := m.Dump()
+	printReport(c, report)
+	return err
+}
+
+func runClean(c *cli, args []string) error {
+	if err := parseFlags(c, flag.NewFlagSet("clean", flag.ContinueOnError), args, 0, 0); err != nil {
+		return err
+	}
+
+	removed, err := c.manager.Clean()
+	for _, filename := range removed {
+		fmt.Fprintf(c.stdout, "removed %s\n", filename)
+	}
+	return err
+}
+
+func runURL(c *cli, args []string) error {
+	flags := flag.NewFlagSet("url", flag.ContinueOnError)
+	if err := parseFlags(c, flags, args, 1, 2); err != nil {
+		return err
+	}
+
+	symlink, err := c.manager.Symlink(flags.Arg(0), flags.Args()[1:]...)
+	if err != nil {
+		return err
+	}
+
+	url, err := c.manager.URLFromSymlink(symlink)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(c.stdout, url)
+	return nil
+}
+
+func runLs(c *cli, args []string) error {
+	if err := parseFlags(c, flag.NewFlagSet("ls", flag.ContinueOnError), args, 0, 0); err != nil {
+		return err
+	}
+
+	return eachFile(c, func(name, path, symlink string) error {
+		url, err := c.manager.URLFromSymlink(symlink)
+		if err != nil {
+			url = "-"
+		}
+		if path == "" {
+			fmt.Fprintf(c.stdout, "%s\t%s\n", name, url)
+		} else {
+			fmt.Fprintf(c.stdout, "%s\t%s\t%s\n", name, path, url)
+		}
+		return nil
+	})
+}
+
+func runVerify(c *cli, args []string) error {
+	if err := parseFlags(c, flag.NewFlagSet("verify", flag.ContinueOnError), args, 0, 0); err != nil {
+		return err
+	}
+
+	invalid := 0
+
+	err := eachFile(c, func(name, path, symlink string) error {
+		if err := verifyFile(symlink); err != nil {
+			fmt.Fprintf(c.stdout, "%s\n", err)
+			invalid++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if invalid > 0 {
+		return fmt.Errorf("%d invalid file(s)", invalid)
+	}
+
+	fmt.Fprintln(c.stdout, "all files are valid")
+	return nil
+}
+
+func runGenerate(c *cli, args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	pkg := flags.String("package", "assets", "package of the generated file")
+	urls := flags.Bool("urls", false, "also generate the urls read from the manifest")
+	out := flags.String("o", "", "generated file (default stdout)")
+	if err := parseFlags(c, flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	var manifest statix.Manifest
+
+	if *urls {
+		if c.manager.Manifest == "" {
+			return errors.New("-urls needs a manifest in the configuration")
+		}
+		var err error
+		manifest, err = statix.ReadManifest(helpers.RewritePath(c.manager.Output, c.manager.Manifest))
+		if err != nil {
+			return err
+		}
+	}
+
+	src, err := c.manager.GenerateGo(*pkg, manifest)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = c.stdout.Write(src)
+		return err
+	}
+
+	return helpers.WriteFile(*out, src, 0644)
+}
+
+// verifyFile checks that `symlink` points to the file
+// named after the md5 hash of its content.
+func verifyFile(symlink string) error {
+	filename, err := filepath.EvalSymlinks(symlink)
+	if err != nil {
+		return fmt.Errorf("%s: symlink is not valid", symlink)
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("%s: %s", symlink, err)
+	}
+
+	expected := helpers.AddFileSuffix(filepath.Base(symlink), "."+helpers.MD5(content))
+	if filepath.Base(filename) != expected {
+		return fmt.Errorf("%s: %s does not match its md5 hash", symlink, filename)
+	}
+
+	return nil
+}
+
+// eachFile calls `fn` for every file of every asset, sorted by asset name.
+// The `path` is empty for a SingleAsset and for the image of a Sprite.
+func eachFile(c *cli, fn func(name, path, symlink string) error) error {
+	m := c.manager
+
+	names := []string{}
+	for name := range m.Assets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		paths := []string{""}
+
+		switch a := m.Assets[name].(type) {
+		case statix.AssetPack:
+			var err error
+			paths, err = a.RewritePaths(m.Input, m.Output).(statix.AssetPack).OutputPaths()
+			if err != nil {
+				return err
+			}
+		case statix.Sprite:
+			paths = a.Paths()
+		}
+
+		for _, path := range paths {
+			symlink, err := m.Symlink(name, path)
+			if err != nil {
+				return err
+			}
+			if err := fn(name, path, symlink); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func printReport(c *cli, report statix.DumpReport) {
+	for _, f := range report.Files {
+		fmt.Fprintf(c.stdout, "%-9s %s\n", f.Status, f.Symlink)
+	}
+	fmt.Fprintf(
+		c.stdout,
+		"%d created, %d updated, %d unchanged\n",
+		len(report.Created()), len(report.Updated()), len(report.Unchanged()),
+	)
+}
+
+// parseFlags parses the flags of a command and loads the manager.
+// The common flags (-config) are added to the `flags` of every command,
+// so they can also be given after the name of the command.
+// The command must have between `min` and `max` arguments after its flags.
+func parseFlags(c *cli, flags *flag.FlagSet, args []string, min, max int) error {
+	configFile := flags.String("config", c.configFile, "configuration file")
+	flags.SetOutput(c.stderr)
+	if err := flags.Parse(args); err != nil {
+		return usageError{err.Error()}
+	}
+
+	switch {
+	case max == 0 && flags.NArg() != 0:
+		return usageError{flags.Name() + " does not take arguments"}
+	case flags.NArg() < min || flags.NArg() > max:
+		return usageError{"wrong number of arguments for " + flags.Name()}
+	}
+
+	c.configFile = *configFile
+	return reloadConfig(c)
+}
@@ -0,0 +1,134 @@
+// Command statix dumps and inspects the assets defined in a configuration file.
+//
+// Usage:
+//
+//	statix [-config statix.json] <command> [arguments]
+//
+// The -config flag can also be given after the name of the command.
+//
+// The commands are:
+//
+//	dump               dump all the assets (-stats prints their sizes and timings)
+//	archive <file>     dump all the assets in a .tar.gz or .zip archive
+//	watch              dump the assets each time an input file changes
+//	clean              remove the files of previous dumps that are not used anymore
+//	plan               show what a dump would do without writing anything
+//	ls                 list the assets and their current urls
+//	url <name> [path]  print the url of an asset
+//	verify             check that the dumped files match their symlinks
+//	generate           write a Go file with a constant for each asset
+//
+// The exit code is 0 on success, 1 if the command failed
+// and 2 if the command line is not valid.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sarulabs/statix"
+)
+
+const (
+	exitSuccess = 0
+	exitFailure = 1
+	exitUsage   = 2
+)
+
+// command is a subcommand of the statix tool.
+type command struct {
+	usage string
+	run   func(cli *cli, args []string) error
+}
+
+var commands = map[string]command{
+	"dump":     {"dump [-stats table|json]", runDump},
+	"archive":  {"archive [-aliases=false] <file>", runArchive},
+	"watch":    {"watch [-interval 1s]", runWatch},
+	"clean":    {"clean", runClean},
+	"plan":     {"plan", runPlan},
+	"ls":       {"ls", runLs},
+	"url":      {"url <name> [path]", runURL},
+	"verify":   {"verify", runVerify},
+	"generate": {"generate [-package assets] [-urls] [-o file]", runGenerate},
+}
+
+var commandOrder = []string{"dump", "archive", "watch", "clean", "plan", "ls", "url", "verify", "generate"}
+
+// cli contains what the commands need to run.
+// The manager is loaded from the configFile by parseFlags.
+type cli struct {
+	configFile string
+	manager    statix.Manager
+	stdout     io.Writer
+	stderr     io.Writer
+}
+
+// usageError is returned by a command when its arguments are not valid.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes the statix command line and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("statix", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configFile := flags.String("config", "statix.json", "configuration file")
+	flags.Usage = func() { usage(stderr, flags) }
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() == 0 {
+		usage(stderr, flags)
+		return exitUsage
+	}
+
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "statix: unknown command `%s`\n", flags.Arg(0))
+		usage(stderr, flags)
+		return exitUsage
+	}
+
+	c := &cli{
+		configFile: *configFile,
+		stdout:     stdout,
+		stderr:     stderr,
+	}
+
+	err := cmd.run(c, flags.Args()[1:])
+	if err == nil {
+		return exitSuccess
+	}
+
+	fmt.Fprintf(stderr, "statix: %s\n", err)
+
+	if _, ok := err.(usageError); ok {
+		fmt.Fprintf(stderr, "usage: statix [-config file] %s\n", cmd.usage)
+		return exitUsage
+	}
+
+	return exitFailure
+}
+
+func usage(w io.Writer, flags *flag.FlagSet) {
+	fmt.Fprintln(w, "usage: statix [-config file] <command> [arguments]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, name := range commandOrder {
+		fmt.Fprintf(w, "  %s\n", commands[name].usage)
+	}
+	fmt.Fprintln(w, "\nflags:")
+	flags.PrintDefaults()
+}
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+const testConfig = `{
+	"input": "in",
+	"output": "out",
+	"server": {"directory": ".", "url": "http://www.example.com/static"},
+	"assets": {
+		"pack": {"type": "pack", "input": "dirIn", "output": "dirOut"},
+		"single": {"type": "single", "output": "single.txt", "resources": [{"string": "single"}]}
+	}
+}`
+
+func createTestDir() string {
+	dir, _ := helpers.TempDir("", "statix_cmd_", "")
+	os.MkdirAll(filepath.Join(dir, "in/dirIn"), 0777)
+	ioutil.WriteFile(filepath.Join(dir, "in/dirIn/a1.txt"), []byte("pack-a1"), 0777)
+	ioutil.WriteFile(filepath.Join(dir, "statix.json"), []byte(testConfig), 0777)
+	return dir
+}
+
+func runTest(dir string, args ...string) (int, string) {
+	stdout := bytes.NewBuffer(nil)
+	code := run(append([]string{"-config", filepath.Join(dir, "statix.json")}, args...), stdout, ioutil.Discard)
+	return code, stdout.String()
+}
+
+func TestRunUsage(t *testing.T) {
+	dir := createTestDir()
+	defer os.RemoveAll(dir)
+
+	if code, _ := runTest(dir); code != exitUsage {
+		t.Error("missing command should return ", exitUsage, " instead of ", code)
+	}
+	if code, _ := runTest(dir, "unknown"); code != exitUsage {
+		t.Error("unknown command should return ", exitUsage, " instead of ", code)
+	}
+	if code, _ := runTest(dir, "dump", "extra"); code != exitUsage {
+		t.Error("invalid arguments should return ", exitUsage, " instead of ", code)
+	}
+	if code, _ := runTest(dir+"/missing", "dump"); code != exitFailure {
+		t.Error("missing configuration should return ", exitFailure, " instead of ", code)
+	}
+}
+
+func TestRunCommands(t *testing.T) {
+	dir := createTestDir()
+	defer os.RemoveAll(dir)
+
+	code, out := runTest(dir, "plan")
+	if code != exitSuccess || !strings.Contains(out, "2 created") {
+		t.Error("plan should show 2 created files instead of ", out)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "out/single.txt")); err == nil {
+		t.Error("plan should not write files")
+	}
+
+	if code, out = runTest(dir, "verify"); code != exitFailure {
+		t.Error("verify should fail before the dump instead of ", out)
+	}
+
+	archive := filepath.Join(dir, "assets.tar.gz")
+	code, out = runTest(dir, "archive", archive)
+	if _, err := os.Stat(archive); code != exitSuccess || err != nil {
+		t.Error("archive should write the archive instead of ", out)
+	}
+	if _, err := os.Lstat(filepath.Join(dir, "out/single.txt")); err == nil {
+		t.Error("archive should not write files in the output directory")
+	}
+	if code, _ = runTest(dir, "archive", filepath.Join(dir, "assets.rar")); code != exitUsage {
+		t.Error("unknown archive format should return ", exitUsage, " instead of ", code)
+	}
+
+	code, out = runTest(dir, "dump")
+	if code != exitSuccess || !strings.Contains(out, "2 created") {
+		t.Error("dump should create 2 files instead of ", out)
+	}
+
+	code, out = runTest(dir, "dump")
+	if code != exitSuccess || !strings.Contains(out, "2 unchanged") {
+		t.Error("second dump should not change files instead of ", out)
+	}
+
+	code, out = runTest(dir, "dump", "-stats", "table")
+	if code != exitSuccess || !strings.Contains(out, "2 files, 2 cache hits") {
+		t.Error("dump -stats table should print the cache hits instead of ", out)
+	}
+
+	code, out = runTest(dir, "dump", "-stats", "json")
+	if code != exitSuccess || !strings.Contains(out, `"cache_hits": 2`) {
+		t.Error("dump -stats json should print the cache hits instead of ", out)
+	}
+
+	if code, _ = runTest(dir, "dump", "-stats", "xml"); code != exitUsage {
+		t.Error("unknown stats format should return ", exitUsage, " instead of ", code)
+	}
+
+	if code, out = runTest(dir, "verify"); code != exitSuccess {
+		t.Error("verify should succeed after the dump instead of ", out)
+	}
+
+	code, out = runTest(dir, "url", "pack", "a1.txt")
+	if code != exitSuccess || out != "http://www.example.com/static/dirOut/a1.df54fa5f220b244f5ed919c871fe56f0.txt\n" {
+		t.Error("url of a1.txt is not correct: ", out)
+	}
+
+	code, out = runTest(dir, "ls")
+	expected := "pack\ta1.txt\thttp://www.example.com/static/dirOut/a1.df54fa5f220b244f5ed919c871fe56f0.txt\n" +
+		"single\thttp://www.example.com/static/single.dd5c07036f2975ff4bce568b6511d3bc.txt\n"
+	if code != exitSuccess || out != expected {
+		t.Error("ls output is not correct: ", out)
+	}
+
+	code, out = runTest(dir, "generate", "-package", "static")
+	if code != exitSuccess || !strings.Contains(out, "package static") || !strings.Contains(out, `PackA1Txt = "a1.txt"`) {
+		t.Error("generate output is not correct: ", out)
+	}
+	if code, _ = runTest(dir, "generate", "-urls"); code != exitFailure {
+		t.Error("generate -urls should fail without a manifest instead of ", code)
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "in/dirIn/a1.txt"), []byte("pack-a1-updated"), 0777)
+	runTest(dir, "dump")
+
+	code, out = runTest(dir, "clean")
+	if code != exitSuccess || !strings.Contains(out, "a1.df54fa5f220b244f5ed919c871fe56f0.txt") {
+		t.Error("clean should remove the old a1.txt instead of ", out)
+	}
+}
+
+func TestRunCommandFlags(t *testing.T) {
+	dir := createTestDir()
+	defer os.RemoveAll(dir)
+
+	stdout := bytes.NewBuffer(nil)
+	config := filepath.Join(dir, "statix.json")
+
+	archive := filepath.Join(dir, "assets.zip")
+	code := run([]string{"archive", "-config", config, "-aliases=false", archive}, stdout, ioutil.Discard)
+	if _, err := os.Stat(archive); code != exitSuccess || err != nil {
+		t.Error("archive should accept the common flags instead of ", stdout.String())
+	}
+
+	if code = run([]string{"plan", "-config", filepath.Join(dir, "missing.json")}, stdout, ioutil.Discard); code != exitFailure {
+		t.Error("the -config flag of a command should be used instead of ", code)
+	}
+	if code, _ = runTest(dir, "archive"); code != exitUsage {
+		t.Error("archive without file should return ", exitUsage, " instead of ", code)
+	}
+}
+
+func TestWatchState(t *testing.T) {
+	dir := createTestDir()
+	defer os.RemoveAll(dir)
+
+	os.MkdirAll(filepath.Join(dir, "vendor/js"), 0777)
+	ioutil.WriteFile(filepath.Join(dir, "vendor/lib.js"), []byte("lib"), 0777)
+	ioutil.WriteFile(filepath.Join(dir, "statix.json"), []byte(`{
+		"input": "in",
+		"output": "out",
+		"server": {"directory": ".", "url": "http://www.example.com/static"},
+		"assets": {
+			"lib": {"type": "single", "output": "lib.js", "resources": [{"file": "../vendor/lib.js"}]},
+			"app": {"type": "single", "output": "app.js", "resources": [{"glob": ["../vendor/js/*.js"]}]}
+		}
+	}`), 0777)
+
+	c := &cli{configFile: filepath.Join(dir, "statix.json"), stdout: ioutil.Discard, stderr: ioutil.Discard}
+	if err := reloadConfig(c); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := watchState(c.configFile, c.manager)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "vendor/lib.js"), []byte("lib-updated"), 0777)
+
+	newState, _ := watchState(c.configFile, c.manager)
+	if newState == state {
+		t.Error("a change of the input of a SingleAsset outside Manager.Input should be detected")
+	}
+
+	ioutil.WriteFile(filepath.Join(dir, "vendor/js/app.js"), []byte("app"), 0777)
+
+	if state, _ = watchState(c.configFile, c.manager); state == newState {
+		t.Error("a new file matching a glob should be detected")
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sarulabs/statix"
+	"github.com/sarulabs/statix/config"
+	"github.com/sarulabs/statix/helpers"
+	"github.com/sarulabs/statix/resource"
+)
+
+// runWatch dumps the assets and dumps them again each time
+// a watched file changes. The watched files are in Manager.Input,
+// the input directories of the AssetPacks and of the Sprites,
+// and the inputs of the SingleAssets (see resourcePaths).
+// The configuration file is also watched and reloaded when it changes.
+// The directories are polled, so no system specific notification is needed.
+func runWatch(c *cli, args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	interval := flags.Duration("interval", time.Second, "polling interval")
+	if err := parseFlags(c, flags, args, 0, 0); err != nil {
+		return err
+	}
+
+	state := ""
+
+	for {
+		newState, err := watchState(c.configFile, c.manager)
+		if err != nil {
+			return err
+		}
+
+		if newState != state {
+			if state != "" {
+				if err := reloadConfig(c); err != nil {
+					fmt.Fprintf(c.stderr, "statix: %s\n", err)
+				}
+				fmt.Fprintf(c.stdout, "%s: changes detected\n", time.Now().Format(time.Kitchen))
+			}
+
+			report, err := c.manager.Dump()
+			printReport(c, report)
+			if err != nil {
+				fmt.Fprintf(c.stderr, "statix: %s\n", err)
+			}
+
+			// the state is computed again to ignore the files dumped
+			// in the watched directories.
+			state, err = watchState(c.configFile, c.manager)
+			if err != nil {
+				return err
+			}
+		}
+
+		time.Sleep(*interval)
+	}
+}
+
+func reloadConfig(c *cli) error {
+	m, err := config.Load(c.configFile)
+	if err != nil {
+		return err
+	}
+	c.manager = m
+	return nil
+}
+
+// watchState returns a string that changes each time a watched file is
+// created, removed or modified.
+func watchState(configFile string, m statix.Manager) (string, error) {
+	buf := bytes.NewBuffer(nil)
+
+	paths := []string{configFile}
+	if m.Input != "" {
+		paths = append(paths, m.Input)
+	}
+	for _, a := range m.Assets {
+		switch a := a.RewritePaths(m.Input, m.Output).(type) {
+		case statix.AssetPack:
+			if a.FS == nil {
+				paths = append(paths, a.Input)
+			}
+		case statix.Sprite:
+			paths = append(paths, a.Input)
+		case statix.SingleAsset:
+			paths = append(paths, resourcePaths(a.Input)...)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		err := filepath.Walk(path, func(filename string, info os.FileInfo, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			fmt.Fprintf(buf, "%s|%d|%d\n", filename, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return helpers.MD5(buf.Bytes()), nil
+}
+
+// resourcePaths returns the paths of the host file system that `r` reads.
+// The files of a Glob are watched through the directories of its patterns,
+// so the new files matching the patterns are also detected.
+// The resources read from an fs.FS are not watched.
+func resourcePaths(r resource.Resource) []string {
+	switch r := r.(type) {
+	case *resource.File:
+		return []string{r.Path}
+	case *resource.Glob:
+		if r.FS != nil {
+			return nil
+		}
+		paths := []string{}
+		for _, pattern := range r.Patterns {
+			paths = append(paths, globDir(pattern))
+		}
+		return paths
+	case *resource.Collection:
+		paths := []string{}
+		for _, child := range r.Resources {
+			paths = append(paths, resourcePaths(child)...)
+		}
+		return paths
+	case *resource.AlteredResource:
+		return resourcePaths(r.Resource)
+	case *resource.Multi:
+		return resourcePaths(r.Resource)
+	case *resource.Template:
+		return resourcePaths(r.Input)
+	case *resource.SymbolSprite:
+		return resourcePaths(r.Input)
+	}
+	return nil
+}
+
+// globDir returns the directory containing all the files matching `pattern`.
+func globDir(pattern string) string {
+	if i := strings.IndexAny(pattern, "*?[{"); i >= 0 {
+		pattern = pattern[:i]
+	}
+	return filepath.Dir(pattern)
+}
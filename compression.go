@@ -0,0 +1,33 @@
+package statix
+
+import (
+	"github.com/sarulabs/statix/resource"
+)
+
+// Compression is the combination of an Alteration, an Extension and a Pattern.
+// It is used to write a compressed sibling next to the dumped files
+// matching the Pattern. The name of the sibling is the name of the file
+// with the md5 suffix followed by the Extension (for example `.gz` or `.br`).
+// The Alteration should compress the content of the resource.
+type Compression struct {
+	Alteration resource.Alteration
+	Extension  string
+	Pattern    Pattern
+}
+
+// Compress applies the Compression.Alteration to `content`.
+// The second returned value is false if the compressed content is not
+// smaller than `content`. In this case, the sibling should not be written.
+func (c Compression) Compress(content []byte) ([]byte, bool, error) {
+	r, err := c.Alteration.Alter(resource.NewBytes(content))
+	if err != nil {
+		return []byte{}, false, err
+	}
+
+	compressed, err := r.Dump()
+	if err != nil {
+		return []byte{}, false, err
+	}
+
+	return compressed, len(compressed) < len(content), nil
+}
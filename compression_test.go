@@ -0,0 +1,145 @@
+package statix
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sarulabs/statix/alteration"
+	"github.com/sarulabs/statix/resource"
+)
+
+func TestCompressionCompress(t *testing.T) {
+	c := Compression{
+		Alteration: alteration.NewGzip(gzip.BestCompression),
+		Extension:  ".gz",
+	}
+
+	content := []byte(strings.Repeat("compress", 100))
+
+	compressed, smaller, err := c.Compress(content)
+	if err != nil {
+		t.Error(err)
+	}
+	if !smaller || len(compressed) >= len(content) {
+		t.Error("compressed content should be smaller")
+	}
+
+	_, smaller, err = c.Compress([]byte("x"))
+	if err != nil {
+		t.Error(err)
+	}
+	if smaller {
+		t.Error("compressing a single byte should not give a smaller content")
+	}
+}
+
+func TestManagerDumpCompressions(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Manifest = "manifest.json"
+	m.Compressions = []Compression{
+		{
+			Alteration: ReverseAlteration{},
+			Extension:  ".rev",
+			Pattern:    NewExtensionPattern("ext"),
+		},
+		{
+			Alteration: alteration.NewGzip(gzip.BestCompression),
+			Extension:  ".gz",
+			Pattern:    NewExtensionPattern("ext"),
+		},
+	}
+	m.Assets["big"] = SingleAsset{
+		Output: "big.ext",
+		Input:  resource.NewString(strings.Repeat("big", 100)),
+	}
+
+	_, err := m.Dump()
+	if err != nil {
+		t.Error(err)
+	}
+
+	manifest, err := ReadManifest("./tests/out/manifest.json")
+	if err != nil {
+		t.Error(err)
+	}
+
+	big, ok := manifest["big.ext"]
+	if !ok {
+		t.Error("big.ext should be in the manifest")
+	}
+	if !strings.HasPrefix(big.File, "big.") || len(big.Compressed) != 1 {
+		t.Error("big.ext should only have a .gz sibling in the manifest")
+	}
+
+	content, err := ioutil.ReadFile("./tests/out/" + big.Compressed[".gz"])
+	if err != nil {
+		t.Error("could not read compressed sibling of big.ext")
+	}
+	if !bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		t.Error("compressed sibling of big.ext should be gzipped")
+	}
+
+	single, ok := manifest["single.ext"]
+	if !ok || single.File != "single.b34c31dcb721861cd51bfa6f3d850524.ext" {
+		t.Error("single.ext is not correct in the manifest")
+	}
+	if len(single.Compressed) != 0 {
+		t.Error("single.ext should not have compressed siblings")
+	}
+
+	if _, ok := manifest["dirOut/a1"]; !ok {
+		t.Error("dirOut/a1 should be in the manifest")
+	}
+}
+
+// countingGzip is a gzip compression counting its calls.
+type countingGzip struct {
+	calls *int
+}
+
+func (cg countingGzip) Alter(r resource.Resource) (resource.Resource, error) {
+	*cg.calls++
+	return alteration.NewGzip(gzip.BestCompression).Alter(r)
+}
+
+func TestManagerDumpUnchangedCompressions(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	calls := 0
+
+	m := getManagerTest()
+	m.Compressions = []Compression{
+		{Alteration: countingGzip{calls: &calls}, Extension: ".gz", Pattern: NewExtensionPattern("txt")},
+	}
+	m.Assets = map[string]Asset{
+		"big": SingleAsset{Output: "big.txt", Input: resource.NewString(strings.Repeat("big", 100))},
+	}
+
+	if _, err := m.Dump(); err != nil || calls != 1 {
+		t.Fatal("the file should be compressed on the first dump: ", calls, err)
+	}
+
+	report, err := m.Dump()
+	if err != nil || calls != 1 {
+		t.Error("an unchanged file should not be compressed again: ", calls, err)
+	}
+	if len(report.Files) != 1 || report.Files[0].Compressed[".gz"] == "" || report.Files[0].CompressedSizes[".gz"] <= 0 {
+		t.Error("the existing sibling should be reported: ", report.Files)
+	}
+
+	os.Remove(report.Files[0].Compressed[".gz"])
+
+	if _, err = m.Dump(); err != nil || calls != 2 {
+		t.Error("a missing sibling should be compressed again: ", calls, err)
+	}
+}
@@ -0,0 +1,413 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/sarulabs/statix"
+	"github.com/sarulabs/statix/helpers"
+	"github.com/sarulabs/statix/resource"
+)
+
+// Config is the declarative definition of a statix.Manager.
+// It can be decoded from a configuration file with Load.
+type Config struct {
+	Input        string           `json:"input" yaml:"input" toml:"input"`
+	Output       string           `json:"output" yaml:"output" toml:"output"`
+	Manifest     string           `json:"manifest" yaml:"manifest" toml:"manifest"`
+	Server       Server           `json:"server" yaml:"server" toml:"server"`
+	Servers      []Server         `json:"servers" yaml:"servers" toml:"servers"`
+	Filters      []Filter         `json:"filters" yaml:"filters" toml:"filters"`
+	Compressions []Compression    `json:"compressions" yaml:"compressions" toml:"compressions"`
+	Dev          bool             `json:"dev" yaml:"dev" toml:"dev"`
+	Assets       map[string]Asset `json:"assets" yaml:"assets" toml:"assets"`
+}
+
+// Server is the definition of a statix.Server.
+type Server struct {
+	Directory string `json:"directory" yaml:"directory" toml:"directory"`
+	URL       string `json:"url" yaml:"url" toml:"url"`
+}
+
+// Pattern is the definition of a statix.Pattern.
+// If Extensions is not empty, the pattern is created with statix.NewExtensionPattern.
+// Otherwise Regexp is used.
+type Pattern struct {
+	Regexp     string   `json:"regexp" yaml:"regexp" toml:"regexp"`
+	Extensions []string `json:"extensions" yaml:"extensions" toml:"extensions"`
+}
+
+// Alteration is the definition of a resource.Alteration.
+// Name is the name used to register its constructor with RegisterAlteration.
+// Params are given to the constructor.
+type Alteration struct {
+	Name   string `json:"name" yaml:"name" toml:"name"`
+	Params Params `json:"params" yaml:"params" toml:"params"`
+}
+
+// Filter is the definition of a statix.Filter.
+type Filter struct {
+	Alteration Alteration `json:"alteration" yaml:"alteration" toml:"alteration"`
+	Pattern    Pattern    `json:"pattern" yaml:"pattern" toml:"pattern"`
+}
+
+// Compression is the definition of a statix.Compression.
+type Compression struct {
+	Alteration Alteration `json:"alteration" yaml:"alteration" toml:"alteration"`
+	Extension  string     `json:"extension" yaml:"extension" toml:"extension"`
+	Pattern    Pattern    `json:"pattern" yaml:"pattern" toml:"pattern"`
+}
+
+// Variant is the definition of a statix.Variant.
+type Variant struct {
+	Suffix     string     `json:"suffix" yaml:"suffix" toml:"suffix"`
+	Descriptor string     `json:"descriptor" yaml:"descriptor" toml:"descriptor"`
+	Pattern    Pattern    `json:"pattern" yaml:"pattern" toml:"pattern"`
+	Alteration Alteration `json:"alteration" yaml:"alteration" toml:"alteration"`
+}
+
+// Alternative is the definition of a statix.Alternative.
+type Alternative struct {
+	Extension   string     `json:"extension" yaml:"extension" toml:"extension"`
+	ContentType string     `json:"content_type" yaml:"content_type" toml:"content_type"`
+	Pattern     Pattern    `json:"pattern" yaml:"pattern" toml:"pattern"`
+	Alteration  Alteration `json:"alteration" yaml:"alteration" toml:"alteration"`
+}
+
+// Asset is the definition of a statix.Asset.
+// Type should be `single` for a statix.SingleAsset, `pack` for a statix.AssetPack
+// or `sprite` for a statix.Sprite.
+//   - a SingleAsset uses Output and Resources. If there are many resources,
+//     they are combined in a resource.Collection.
+//   - an AssetPack uses Input, Output, Pattern, Alterations, Variants, Alternatives and Rename.
+//     Rename maps the extensions of the input files to the extensions of the outputs.
+//   - a Sprite uses Input, Output, CSS, JSON, Class and Padding.
+type Asset struct {
+	Type         string            `json:"type" yaml:"type" toml:"type"`
+	Input        string            `json:"input" yaml:"input" toml:"input"`
+	Output       string            `json:"output" yaml:"output" toml:"output"`
+	Pattern      Pattern           `json:"pattern" yaml:"pattern" toml:"pattern"`
+	Alterations  []Alteration      `json:"alterations" yaml:"alterations" toml:"alterations"`
+	Variants     []Variant         `json:"variants" yaml:"variants" toml:"variants"`
+	Alternatives []Alternative     `json:"alternatives" yaml:"alternatives" toml:"alternatives"`
+	Rename       map[string]string `json:"rename" yaml:"rename" toml:"rename"`
+	Resources    []Resource        `json:"resources" yaml:"resources" toml:"resources"`
+	CSS          string            `json:"css" yaml:"css" toml:"css"`
+	JSON         string            `json:"json" yaml:"json" toml:"json"`
+	Class        string            `json:"class" yaml:"class" toml:"class"`
+	Padding      int               `json:"padding" yaml:"padding" toml:"padding"`
+}
+
+// Resource is the definition of a resource.Resource.
+// Only one of File, String, Glob, URL, Template, Collection and Symbols should be defined.
+// Exclude contains the excludes of a resource.Glob.
+// Symbols is the input of a resource.SymbolSprite and Prefix the prefix of its ids.
+// Hash, CacheDir and Offline are the options of a resource.URL.
+// Template is the resource containing the source of a resource.Template
+// executed with Data. The template can use the functions created
+// by statix.Manager.TemplateScope.
+// If there are some Alterations, the resource is wrapped
+// in a resource.AlteredResource.
+type Resource struct {
+	File        string                 `json:"file" yaml:"file" toml:"file"`
+	String      *string                `json:"string" yaml:"string" toml:"string"`
+	Glob        []string               `json:"glob" yaml:"glob" toml:"glob"`
+	Exclude     []string               `json:"exclude" yaml:"exclude" toml:"exclude"`
+	URL         string                 `json:"url" yaml:"url" toml:"url"`
+	Hash        string                 `json:"hash" yaml:"hash" toml:"hash"`
+	CacheDir    string                 `json:"cache_dir" yaml:"cache_dir" toml:"cache_dir"`
+	Offline     bool                   `json:"offline" yaml:"offline" toml:"offline"`
+	Template    *Resource              `json:"template" yaml:"template" toml:"template"`
+	Data        map[string]interface{} `json:"data" yaml:"data" toml:"data"`
+	Collection  []Resource             `json:"collection" yaml:"collection" toml:"collection"`
+	Symbols     *Resource              `json:"symbols" yaml:"symbols" toml:"symbols"`
+	Prefix      string                 `json:"prefix" yaml:"prefix" toml:"prefix"`
+	Alterations []Alteration           `json:"alterations" yaml:"alterations" toml:"alterations"`
+}
+
+// Load reads a configuration file and returns the Manager it defines.
+// The Decoder used to read the file is chosen from the file extension
+// (see RegisterDecoder). If the Config.Input or Config.Output is relative,
+// it is based in the directory of the configuration file.
+func Load(filename string) (statix.Manager, error) {
+	c, err := Read(filename)
+	if err != nil {
+		return statix.Manager{}, err
+	}
+	return c.Manager()
+}
+
+// Read reads a configuration file and returns the Config it contains.
+// The relative Config.Input and Config.Output are rewritten
+// to be based in the directory of the configuration file.
+func Read(filename string) (Config, error) {
+	c := Config{}
+
+	decoder, ok := getDecoder(filepath.Ext(filename))
+	if !ok {
+		return c, fmt.Errorf("no decoder for configuration file `%s` (see config.RegisterDecoder)", filename)
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return c, err
+	}
+
+	err = decoder(content, &c)
+	if err != nil {
+		return c, fmt.Errorf("could not decode configuration file `%s`: %s", filename, err)
+	}
+
+	dir := filepath.Dir(filename)
+	c.Input = helpers.RewritePath(dir, c.Input)
+	c.Output = helpers.RewritePath(dir, c.Output)
+
+	return c, nil
+}
+
+// Manager creates the statix.Manager defined by the Config.
+func (c Config) Manager() (statix.Manager, error) {
+	m := statix.Manager{
+		Input:    c.Input,
+		Output:   c.Output,
+		Manifest: c.Manifest,
+		Server:   statix.Server(c.Server),
+		Dev:      c.Dev,
+		Assets:   map[string]statix.Asset{},
+	}
+
+	// the functions share the Assets map with the returned Manager,
+	// so templates can reference any asset.
+	scope := m.TemplateScope()
+
+	for _, s := range c.Servers {
+		m.Servers = append(m.Servers, statix.Server(s))
+	}
+
+	for _, f := range c.Filters {
+		a, err := f.Alteration.Alteration()
+		if err != nil {
+			return m, err
+		}
+		m.Filters = append(m.Filters, statix.Filter{
+			Alteration: a,
+			Pattern:    f.Pattern.Pattern(),
+		})
+	}
+
+	for _, comp := range c.Compressions {
+		a, err := comp.Alteration.Alteration()
+		if err != nil {
+			return m, err
+		}
+		m.Compressions = append(m.Compressions, statix.Compression{
+			Alteration: a,
+			Extension:  comp.Extension,
+			Pattern:    comp.Pattern.Pattern(),
+		})
+	}
+
+	for name, a := range c.Assets {
+		asset, err := a.asset(scope)
+		if err != nil {
+			return m, fmt.Errorf("asset `%s`: %s", name, err)
+		}
+		m.Assets[name] = asset
+	}
+
+	return m, nil
+}
+
+// Pattern creates the statix.Pattern defined by the Pattern.
+func (p Pattern) Pattern() statix.Pattern {
+	if len(p.Extensions) > 0 {
+		return statix.NewExtensionPattern(p.Extensions...)
+	}
+	return statix.NewPattern(p.Regexp)
+}
+
+// Alteration creates the resource.Alteration defined by the Alteration
+// with the constructor registered under Alteration.Name.
+func (a Alteration) Alteration() (resource.Alteration, error) {
+	constructor, ok := getAlteration(a.Name)
+	if !ok {
+		return nil, fmt.Errorf("alteration `%s` is not registered", a.Name)
+	}
+
+	alteration, err := constructor(a.Params)
+	if err != nil {
+		return nil, fmt.Errorf("alteration `%s`: %s", a.Name, err)
+	}
+
+	return alteration, nil
+}
+
+// Asset creates the statix.Asset defined by the Asset.
+// Templates included in the asset can not use the functions of a Manager.
+// Use Config.Manager to create assets with templates.
+func (a Asset) Asset() (statix.Asset, error) {
+	return a.asset(nil)
+}
+
+func (a Asset) asset(scope resource.FuncsScope) (statix.Asset, error) {
+	switch a.Type {
+	case "single":
+		return a.singleAsset(scope)
+	case "pack":
+		return a.assetPack()
+	case "sprite":
+		return a.sprite(), nil
+	}
+	return nil, fmt.Errorf("type should be `single`, `pack` or `sprite` instead of `%s`", a.Type)
+}
+
+func (a Asset) singleAsset(scope resource.FuncsScope) (statix.Asset, error) {
+	if len(a.Resources) == 0 {
+		return nil, errors.New("a single asset needs at least one resource")
+	}
+
+	r, err := Resource{Collection: a.Resources}.resource(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	if c := r.(*resource.Collection); len(c.Resources) == 1 {
+		r = c.Resources[0]
+	}
+
+	return statix.SingleAsset{
+		Input:  r,
+		Output: a.Output,
+		Dumper: statix.FileDumper{},
+	}, nil
+}
+
+func (a Asset) assetPack() (statix.Asset, error) {
+	ap := statix.AssetPack{
+		Input:      a.Input,
+		Output:     a.Output,
+		Pattern:    a.Pattern.Pattern(),
+		Extensions: a.Rename,
+		Dumper:     statix.FileDumper{},
+	}
+
+	for _, alt := range a.Alterations {
+		alteration, err := alt.Alteration()
+		if err != nil {
+			return nil, err
+		}
+		ap.Alterations = append(ap.Alterations, alteration)
+	}
+
+	for _, v := range a.Variants {
+		alteration, err := v.Alteration.Alteration()
+		if err != nil {
+			return nil, err
+		}
+		ap.Variants = append(ap.Variants, statix.Variant{
+			Suffix:     v.Suffix,
+			Descriptor: v.Descriptor,
+			Pattern:    v.Pattern.Pattern(),
+			Alteration: alteration,
+		})
+	}
+
+	for _, alt := range a.Alternatives {
+		alteration, err := alt.Alteration.Alteration()
+		if err != nil {
+			return nil, err
+		}
+		ap.Alternatives = append(ap.Alternatives, statix.Alternative{
+			Extension:   alt.Extension,
+			ContentType: alt.ContentType,
+			Pattern:     alt.Pattern.Pattern(),
+			Alteration:  alteration,
+		})
+	}
+
+	return ap, nil
+}
+
+func (a Asset) sprite() statix.Asset {
+	return statix.Sprite{
+		Input:   a.Input,
+		Output:  a.Output,
+		CSS:     a.CSS,
+		JSON:    a.JSON,
+		Class:   a.Class,
+		Padding: a.Padding,
+		Dumper:  statix.FileDumper{},
+	}
+}
+
+// Resource creates the resource.Resource defined by the Resource.
+// Templates can not use the functions of a Manager.
+// Use Config.Manager to create resources with templates.
+func (r Resource) Resource() (resource.Resource, error) {
+	return r.resource(nil)
+}
+
+func (r Resource) resource(scope resource.FuncsScope) (resource.Resource, error) {
+	var res resource.Resource
+
+	switch {
+	case r.File != "":
+		res = resource.NewFile(r.File)
+	case r.String != nil:
+		res = resource.NewString(*r.String)
+	case r.Glob != nil:
+		g := resource.NewGlob(r.Glob...)
+		g.Excludes = r.Exclude
+		res = g
+	case r.URL != "":
+		u := resource.NewURL(r.URL, r.Hash)
+		u.CacheDir = r.CacheDir
+		u.Offline = r.Offline
+		res = u
+	case r.Template != nil:
+		input, err := r.Template.resource(scope)
+		if err != nil {
+			return nil, err
+		}
+		tmpl := resource.NewTemplate(input, r.Data, nil)
+		tmpl.Scope = scope
+		res = tmpl
+	case r.Collection != nil:
+		c := resource.NewCollection()
+		for _, child := range r.Collection {
+			childRes, err := child.resource(scope)
+			if err != nil {
+				return nil, err
+			}
+			c.Resources = append(c.Resources, childRes)
+		}
+		res = c
+	case r.Symbols != nil:
+		input, err := r.Symbols.resource(scope)
+		if err != nil {
+			return nil, err
+		}
+		s := resource.NewSymbolSprite(input)
+		s.Prefix = r.Prefix
+		res = s
+	default:
+		return nil, errors.New("a resource should have a file, a string, a glob, an url, a template, a collection or symbols")
+	}
+
+	if len(r.Alterations) == 0 {
+		return res, nil
+	}
+
+	ar := resource.NewAlteredResource(res)
+	for _, alt := range r.Alterations {
+		alteration, err := alt.Alteration()
+		if err != nil {
+			return nil, err
+		}
+		ar.Alterations = append(ar.Alterations, alteration)
+	}
+
+	return ar, nil
+}
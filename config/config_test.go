@@ -0,0 +1,264 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sarulabs/statix"
+	"github.com/sarulabs/statix/alteration"
+	"github.com/sarulabs/statix/helpers"
+	"github.com/sarulabs/statix/resource"
+)
+
+const testConfig = `{
+	"input": "in",
+	"output": "/out",
+	"manifest": "manifest.json",
+	"server": {"directory": ".", "url": "http://www.example.com/static"},
+	"filters": [
+		{"alteration": {"name": "uglifyjs", "params": {"bin": "/bin/uglifyjs"}}, "pattern": {"extensions": ["js"]}}
+	],
+	"compressions": [
+		{"alteration": {"name": "gzip", "params": {"level": 6}}, "extension": ".gz", "pattern": {"regexp": "\\.css$"}}
+	],
+	"assets": {
+		"app-js": {
+			"type": "single",
+			"output": "app.js",
+			"resources": [
+				{"file": "js/jquery.js"},
+				{"string": "var x = 1;"},
+				{"glob": ["js/components/*.js"], "exclude": ["*.min.js"]},
+				{"url": "http://example.com/lib.js", "hash": "sha256:00", "cache_dir": "cache", "offline": true},
+				{"file": "app.ts", "alterations": [{"name": "typescript"}]}
+			]
+		},
+		"images": {
+			"type": "pack",
+			"input": "img",
+			"output": "img",
+			"pattern": {"extensions": ["png"]},
+			"alterations": [{"name": "optipng", "params": {"level": 7}}],
+			"rename": {"styl": "css"},
+			"variants": [{"suffix": "-320w", "descriptor": "320w", "alteration": {"name": "resize", "params": {"width": 320}}}],
+			"alternatives": [{"extension": ".webp", "content_type": "image/webp", "alteration": {"name": "cwebp", "params": {"quality": 80}}}]
+		},
+		"symbols": {
+			"type": "single",
+			"output": "symbols.svg",
+			"resources": [{"symbols": {"glob": ["icons/*.svg"]}, "prefix": "i-"}]
+		},
+		"icons": {
+			"type": "sprite",
+			"input": "icons",
+			"output": "img/icons.png",
+			"css": "css/icons.css",
+			"class": "i",
+			"padding": 2
+		}
+	}
+}`
+
+func writeTestConfig(name, content string) (string, func()) {
+	dir, _ := helpers.TempDir("", "statix_config_", "")
+	filename := filepath.Join(dir, name)
+	ioutil.WriteFile(filename, []byte(content), 0644)
+	return filename, func() { os.RemoveAll(dir) }
+}
+
+func TestLoad(t *testing.T) {
+	filename, clean := writeTestConfig("statix.json", testConfig)
+	defer clean()
+
+	m, err := Load(filename)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if m.Input != filepath.Join(filepath.Dir(filename), "in") || m.Output != "/out" {
+		t.Error("relative input should be based in the configuration directory")
+	}
+	if m.Manifest != "manifest.json" || m.Server.URL != "http://www.example.com/static" {
+		t.Error("manifest and server are not correct")
+	}
+
+	if len(m.Filters) != 1 || m.Filters[0].Alteration != alteration.NewUglifyJs("/bin/uglifyjs") {
+		t.Error("filter alteration is not correct")
+	}
+	if !m.Filters[0].Pattern.Match("app.js") || m.Filters[0].Pattern.Match("app.css") {
+		t.Error("filter pattern is not correct")
+	}
+
+	if len(m.Compressions) != 1 || m.Compressions[0].Alteration != alteration.NewGzip(6) {
+		t.Error("compression alteration is not correct")
+	}
+	if m.Compressions[0].Extension != ".gz" || !m.Compressions[0].Pattern.Match("app.css") {
+		t.Error("compression is not correct")
+	}
+
+	single, ok := m.Assets["app-js"].(statix.SingleAsset)
+	if !ok || single.Output != "app.js" {
+		t.Error("app-js should be a SingleAsset")
+		return
+	}
+	c, ok := single.Input.(*resource.Collection)
+	if !ok || len(c.Resources) != 5 {
+		t.Error("app-js input should be a collection of 5 resources")
+		return
+	}
+	if f, ok := c.Resources[0].(*resource.File); !ok || f.Path != "js/jquery.js" {
+		t.Error("first resource of app-js should be a File")
+	}
+	if b, ok := c.Resources[1].(*resource.Bytes); !ok || string(b.Content) != "var x = 1;" {
+		t.Error("second resource of app-js should be a Bytes")
+	}
+	g, ok := c.Resources[2].(*resource.Glob)
+	if !ok || g.Patterns[0] != "js/components/*.js" || g.Excludes[0] != "*.min.js" {
+		t.Error("third resource of app-js should be a Glob")
+	}
+	u, ok := c.Resources[3].(*resource.URL)
+	if !ok || u.URL != "http://example.com/lib.js" || u.Hash != "sha256:00" || u.CacheDir != "cache" || !u.Offline {
+		t.Error("fourth resource of app-js should be an URL")
+	}
+	ar, ok := c.Resources[4].(*resource.AlteredResource)
+	if !ok || len(ar.Alterations) != 1 || ar.Alterations[0] != alteration.NewTypeScript("tsc") {
+		t.Error("fifth resource of app-js should be an AlteredResource")
+	}
+
+	pack, ok := m.Assets["images"].(statix.AssetPack)
+	if !ok || pack.Input != "img" || pack.Output != "img" {
+		t.Error("images should be an AssetPack")
+		return
+	}
+	if !pack.Pattern.Match("logo.png") || len(pack.Alterations) != 1 {
+		t.Error("images pattern and alterations are not correct")
+	}
+	if pack.Alterations[0] != alteration.NewOptiPng("optipng", 7) {
+		t.Error("images alteration is not correct")
+	}
+	if pack.Extensions["styl"] != "css" {
+		t.Error("images rename is not correct")
+	}
+	if len(pack.Variants) != 1 || pack.Variants[0].Suffix != "-320w" || pack.Variants[0].Descriptor != "320w" {
+		t.Error("images variants are not correct")
+		return
+	}
+	if pack.Variants[0].Alteration != alteration.NewResize(320) {
+		t.Error("images variant alteration is not correct")
+	}
+	if len(pack.Alternatives) != 1 || pack.Alternatives[0].Extension != ".webp" || pack.Alternatives[0].ContentType != "image/webp" {
+		t.Error("images alternatives are not correct")
+		return
+	}
+	if pack.Alternatives[0].Alteration != alteration.NewCWebp("cwebp", 80) {
+		t.Error("images alternative alteration is not correct")
+	}
+
+	symbols, ok := m.Assets["symbols"].(statix.SingleAsset)
+	if !ok {
+		t.Error("symbols should be a SingleAsset")
+		return
+	}
+	if s, ok := symbols.Input.(*resource.SymbolSprite); !ok || s.Prefix != "i-" {
+		t.Error("symbols input should be a SymbolSprite")
+	} else if g, ok := s.Input.(*resource.Glob); !ok || g.Patterns[0] != "icons/*.svg" {
+		t.Error("symbols input should contain a Glob")
+	}
+
+	sprite, ok := m.Assets["icons"].(statix.Sprite)
+	if !ok || sprite.Input != "icons" || sprite.Output != "img/icons.png" || sprite.CSS != "css/icons.css" {
+		t.Error("icons should be a Sprite")
+	}
+	if sprite.JSON != "" || sprite.Class != "i" || sprite.Padding != 2 {
+		t.Error("icons options are not correct")
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	filename, clean := writeTestConfig("statix.unknown", "{}")
+	defer clean()
+
+	if _, err := Load(filename); err == nil {
+		t.Error("a file without decoder should not be loaded")
+	}
+
+	filename, clean = writeTestConfig("statix.json", `{"filters": [{"alteration": {"name": "missing"}}]}`)
+	defer clean()
+
+	if _, err := Load(filename); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Error("an unregistered alteration should return an error")
+	}
+
+	filename, clean = writeTestConfig("statix.json", `{"assets": {"a": {"type": "other"}}}`)
+	defer clean()
+
+	if _, err := Load(filename); err == nil {
+		t.Error("an unknown asset type should return an error")
+	}
+
+	filename, clean = writeTestConfig("statix.json", `{"filters": [{"alteration": {"name": "gzip", "params": {"level": "high"}}}]}`)
+	defer clean()
+
+	if _, err := Load(filename); err == nil {
+		t.Error("an invalid parameter should return an error")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	RegisterDecoder(".lines", func(data []byte, v interface{}) error {
+		c := v.(*Config)
+		c.Output = strings.TrimSpace(string(data))
+		return nil
+	})
+	RegisterAlteration("custom", func(p Params) (resource.Alteration, error) {
+		bin, err := p.String("bin", "default")
+		return alteration.NewUglifyJs(bin), err
+	})
+
+	filename, clean := writeTestConfig("statix.lines", "/custom/output\n")
+	defer clean()
+
+	m, err := Load(filename)
+	if err != nil || m.Output != "/custom/output" {
+		t.Error("registered decoder should be used")
+	}
+
+	a, err := Alteration{Name: "custom"}.Alteration()
+	if err != nil || a != alteration.NewUglifyJs("default") {
+		t.Error("registered alteration should be used")
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	filename, clean := writeTestConfig("statix.json", `{
+		"server": {"directory": ".", "url": "/static"},
+		"assets": {
+			"single": {"type": "single", "output": "single.txt", "resources": [{"string": "single"}]},
+			"config": {"type": "single", "output": "config.js", "resources": [
+				{"template": {"string": "{{.env}} {{asset_url \"single\"}}"}, "data": {"env": "prod"}}
+			]}
+		}
+	}`)
+	defer clean()
+
+	m, err := Load(filename)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	tmpl, ok := m.Assets["config"].(statix.SingleAsset).Input.(*resource.Template)
+	if !ok {
+		t.Error("config input should be a Template")
+		return
+	}
+
+	content, err := tmpl.Dump()
+	if err != nil || string(content) != "prod /static/single.dd5c07036f2975ff4bce568b6511d3bc.txt" {
+		t.Error("template is not correct: ", string(content), err)
+	}
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"math"
+)
+
+// Params are the parameters given to an AlterationConstructor.
+type Params map[string]interface{}
+
+// String returns the parameter `name` as a string.
+// If the parameter is not defined, `def` is returned.
+func (p Params) String(name, def string) (string, error) {
+	v, ok := p[name]
+	if !ok {
+		return def, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return def, fmt.Errorf("parameter `%s` should be a string", name)
+	}
+	return s, nil
+}
+
+// Int returns the parameter `name` as an int.
+// If the parameter is not defined, `def` is returned.
+func (p Params) Int(name string, def int) (int, error) {
+	v, ok := p[name]
+	if !ok {
+		return def, nil
+	}
+	switch i := v.(type) {
+	case int:
+		return i, nil
+	case int64:
+		return int(i), nil
+	case uint64:
+		return int(i), nil
+	case float64:
+		if i == math.Trunc(i) {
+			return int(i), nil
+		}
+	}
+	return def, fmt.Errorf("parameter `%s` should be an integer", name)
+}
+
+// Bool returns the parameter `name` as a bool.
+// If the parameter is not defined, `def` is returned.
+func (p Params) Bool(name string, def bool) (bool, error) {
+	v, ok := p[name]
+	if !ok {
+		return def, nil
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return def, fmt.Errorf("parameter `%s` should be a boolean", name)
+	}
+	return b, nil
+}
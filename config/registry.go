@@ -0,0 +1,166 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/sarulabs/statix/alteration"
+	"github.com/sarulabs/statix/resource"
+)
+
+// Decoder decodes the content of a configuration file into `v`.
+// json.Unmarshal is a Decoder, but so are the Unmarshal functions
+// of most yaml and toml packages.
+type Decoder func(data []byte, v interface{}) error
+
+// AlterationConstructor creates an Alteration from its parameters.
+type AlterationConstructor func(Params) (resource.Alteration, error)
+
+var (
+	registryMu  sync.RWMutex
+	decoders    = map[string]Decoder{}
+	alterations = map[string]AlterationConstructor{}
+)
+
+// RegisterDecoder registers the Decoder used to read
+// the configuration files with the extension `ext` (for example `.yaml`).
+// The `.json` extension is registered by default.
+// Configuration structures have json, yaml and toml tags, so a yaml
+// or a toml decoder can be registered directly:
+//
+//	config.RegisterDecoder(".yaml", yaml.Unmarshal)
+//	config.RegisterDecoder(".toml", toml.Unmarshal)
+func RegisterDecoder(ext string, d Decoder) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	decoders[strings.ToLower(ext)] = d
+}
+
+// RegisterAlteration registers an AlterationConstructor. The alteration
+// can then be used in a configuration file under the name `name`.
+// The alterations of the alteration package are registered by default.
+func RegisterAlteration(name string, c AlterationConstructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	alterations[name] = c
+}
+
+func getDecoder(ext string) (Decoder, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	d, ok := decoders[strings.ToLower(ext)]
+	return d, ok
+}
+
+func getAlteration(name string) (AlterationConstructor, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	c, ok := alterations[name]
+	return c, ok
+}
+
+func init() {
+	RegisterDecoder(".json", json.Unmarshal)
+
+	RegisterAlteration("avifenc", func(p Params) (resource.Alteration, error) {
+		bin, err := p.String("bin", "avifenc")
+		if err != nil {
+			return nil, err
+		}
+		quality, err := p.Int("quality", 60)
+		return alteration.NewAvifEnc(bin, quality), err
+	})
+
+	RegisterAlteration("brotli", func(p Params) (resource.Alteration, error) {
+		bin, err := p.String("bin", "brotli")
+		if err != nil {
+			return nil, err
+		}
+		quality, err := p.Int("quality", 11)
+		return alteration.NewBrotli(bin, quality), err
+	})
+
+	RegisterAlteration("cwebp", func(p Params) (resource.Alteration, error) {
+		bin, err := p.String("bin", "cwebp")
+		if err != nil {
+			return nil, err
+		}
+		quality, err := p.Int("quality", 75)
+		return alteration.NewCWebp(bin, quality), err
+	})
+
+	RegisterAlteration("datauri", func(p Params) (resource.Alteration, error) {
+		maxSize, err := p.Int("max_size", 4096)
+		if err != nil {
+			return nil, err
+		}
+		dir, err := p.String("dir", "")
+		return alteration.DataURI{MaxSize: int64(maxSize), Dir: dir}, err
+	})
+
+	RegisterAlteration("gzip", func(p Params) (resource.Alteration, error) {
+		level, err := p.Int("level", 9)
+		return alteration.NewGzip(level), err
+	})
+
+	RegisterAlteration("jpegoptim", func(p Params) (resource.Alteration, error) {
+		bin, err := p.String("bin", "jpegoptim")
+		if err != nil {
+			return nil, err
+		}
+		stripAll, err := p.Bool("strip_all", true)
+		if err != nil {
+			return nil, err
+		}
+		max, err := p.Int("max", 100)
+		return alteration.NewJpegOptim(bin, stripAll, max), err
+	})
+
+	RegisterAlteration("optimizejpeg", func(p Params) (resource.Alteration, error) {
+		quality, err := p.Int("quality", 85)
+		return alteration.NewOptimizeJpeg(quality), err
+	})
+
+	RegisterAlteration("optimizepng", func(p Params) (resource.Alteration, error) {
+		return alteration.NewOptimizePng(), nil
+	})
+
+	RegisterAlteration("optipng", func(p Params) (resource.Alteration, error) {
+		bin, err := p.String("bin", "optipng")
+		if err != nil {
+			return nil, err
+		}
+		level, err := p.Int("level", 2)
+		return alteration.NewOptiPng(bin, level), err
+	})
+
+	RegisterAlteration("resize", func(p Params) (resource.Alteration, error) {
+		width, err := p.Int("width", 0)
+		if err != nil {
+			return nil, err
+		}
+		quality, err := p.Int("quality", 85)
+		return alteration.Resize{Width: width, Quality: quality}, err
+	})
+
+	RegisterAlteration("stylus", func(p Params) (resource.Alteration, error) {
+		bin, err := p.String("bin", "stylus")
+		return alteration.NewStylus(bin), err
+	})
+
+	RegisterAlteration("typescript", func(p Params) (resource.Alteration, error) {
+		bin, err := p.String("bin", "tsc")
+		return alteration.NewTypeScript(bin), err
+	})
+
+	RegisterAlteration("uglifycss", func(p Params) (resource.Alteration, error) {
+		bin, err := p.String("bin", "uglifycss")
+		return alteration.NewUglifyCss(bin), err
+	})
+
+	RegisterAlteration("uglifyjs", func(p Params) (resource.Alteration, error) {
+		bin, err := p.String("bin", "uglifyjs")
+		return alteration.NewUglifyJs(bin), err
+	})
+}
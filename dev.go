@@ -0,0 +1,191 @@
+package statix
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+// DevHandler returns an http.Handler serving the assets without dumping them.
+// It is meant to be used in development with Manager.Dev set to true,
+// so that Manager.URL returns urls handled by the DevHandler.
+//
+// The requested url is converted into a filename with the servers defined
+// in Manager.Server and Manager.Servers. Then:
+//   - if the filename is the output of a SingleAsset, its input is dumped on each request.
+//     Alterations included in the input are applied, but not Manager.Filters.
+//   - if the filename is in the output directory of an AssetPack, the matching file
+//     in the input directory is served as it is. The variants of a file are served
+//     without their Variant.Alteration, as the file itself. But if an alteration
+//     renames the file (see resource.Renamer), AssetPack.Alterations are applied.
+//   - if the filename is a file of a Sprite, the sprite is dumped in memory on each request.
+//     Its image is served both with and without its md5 suffix.
+func (m Manager) DevHandler() http.Handler {
+	return devHandler{manager: m}
+}
+
+type devHandler struct {
+	manager Manager
+}
+
+func (h devHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filename, err := h.manager.FilenameFromURL(r.URL.Path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	content, found, err := h.manager.devContent(filename)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, filename, time.Time{}, bytes.NewReader(content))
+}
+
+// devContent returns the content that the DevHandler serves for `filename`.
+// The second returned value is false if no asset matches `filename`.
+func (m Manager) devContent(filename string) ([]byte, bool, error) {
+	input, err := filepath.Abs(m.Input)
+	if err != nil {
+		return nil, false, err
+	}
+
+	output, err := filepath.Abs(m.Output)
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, a := range m.Assets {
+		switch a := a.RewritePaths(input, output).(type) {
+		case SingleAsset:
+			out, err := a.OutputFile("")
+			if err != nil || out != filename {
+				continue
+			}
+			c, err := a.Input.Dump()
+			return c, true, err
+
+		case AssetPack:
+			dir, err := filepath.Abs(a.Output)
+			if err != nil || !strings.HasPrefix(filename, dir+string(filepath.Separator)) {
+				continue
+			}
+			inputFile, _, ok := a.source(filename[len(dir):])
+			if !ok {
+				continue
+			}
+			r := a.resource(inputFile)
+			if a.rename(inputFile) != inputFile {
+				// the file is compiled into another type,
+				// so it can not be served as it is
+				r, _, err = a.alter(r, nil)
+				if err != nil {
+					return nil, true, err
+				}
+			}
+			c, err := r.Dump()
+			return c, true, err
+
+		case Sprite:
+			c, found, err := a.devContent(output, filename)
+			if !found {
+				continue
+			}
+			return c, true, err
+		}
+	}
+
+	return nil, false, nil
+}
+
+// FilenameFromURL returns the filename matching an url path.
+// It is the opposite of Manager.URLFromFilename. The path of the url
+// of each server is compared to `urlPath`. If one of them is a prefix,
+// the rest of `urlPath` is added to the directory of the server.
+func (m Manager) FilenameFromURL(urlPath string) (string, error) {
+	urlPath = path.Clean("/" + urlPath)
+
+	servers := append([]Server{m.Server}, m.Servers...)
+
+	for _, s := range servers {
+		u, err := url.Parse(s.URL)
+		if err != nil {
+			continue
+		}
+
+		prefix := path.Clean("/" + u.Path)
+		if prefix != "/" && urlPath != prefix && !strings.HasPrefix(urlPath, prefix+"/") {
+			continue
+		}
+
+		dir := helpers.RewritePath(m.Output, s.Directory)
+		dir, err = filepath.Abs(dir)
+		if err != nil {
+			return "", err
+		}
+
+		return filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(urlPath, prefix))), nil
+	}
+
+	return "", fmt.Errorf("no server for url `%s`", urlPath)
+}
+
+// devContent dumps the Sprite in a MemoryDumper and returns
+// the content of `filename`. The second returned value is false
+// if `filename` is not a file of the Sprite: its Output, CSS or JSON,
+// with or without md5 suffix (the css references the image with its suffix).
+func (sp Sprite) devContent(output, filename string) ([]byte, bool, error) {
+	found := false
+	for _, p := range sp.Paths() {
+		out, err := sp.OutputFile(p, "")
+		if err == nil && (out == filename || out == removeMD5Suffix(filename)) {
+			found = true
+		}
+	}
+	if !found {
+		return nil, false, nil
+	}
+
+	md := NewMemoryDumper(output)
+	sp.Dumper = md
+
+	if _, err := sp.Dump(nil, nil); err != nil {
+		return nil, true, err
+	}
+
+	name, err := md.name(filename)
+	if err != nil {
+		return nil, false, nil
+	}
+
+	c, err := fs.ReadFile(md, name)
+	if err != nil {
+		return nil, false, nil
+	}
+	return c, true, nil
+}
+
+// md5SuffixRegexp matches the md5 suffix added to a filename
+// by helpers.AddFileSuffix, followed by the extension of the file.
+var md5SuffixRegexp = regexp.MustCompile(`\.[0-9a-f]{32}(\.[^./\\]*)?$`)
+
+// removeMD5Suffix returns `filename` without its md5 suffix.
+func removeMD5Suffix(filename string) string {
+	return md5SuffixRegexp.ReplaceAllString(filename, "${1}")
+}
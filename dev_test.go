@@ -0,0 +1,119 @@
+package statix
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+func TestManagerDevURL(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Dev = true
+
+	url := m.URL("single")
+	expected := "http://www.example.com/static/single.ext"
+	if url != expected {
+		t.Error("url should be ", expected, " instead of ", url)
+	}
+
+	url = m.URL("pack", "subDir/a2.ext")
+	expected = "http://www.example.com/static/dirOut/subDir/a2.ext"
+	if url != expected {
+		t.Error("url should be ", expected, " instead of ", url)
+	}
+
+	if _, err := os.Stat("./tests/out"); err == nil {
+		t.Error("dev urls should not need a dump")
+	}
+}
+
+func TestManagerDevHandler(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Dev = true
+	h := m.DevHandler()
+
+	tests := []struct {
+		path string
+		code int
+		body string
+	}{
+		{"/static/single.ext", http.StatusOK, "single"},
+		{"/static/dirOut/a1", http.StatusOK, "pack-a1"},
+		{"/static/dirOut/subDir/a2.ext", http.StatusOK, "pack-a2"},
+		{"/static/dirOut/missing", http.StatusNotFound, ""},
+		{"/other/single.ext", http.StatusNotFound, ""},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", test.path, nil))
+
+		if w.Code != test.code {
+			t.Error(test.path, " should return ", test.code, " instead of ", w.Code)
+		}
+		if test.code == http.StatusOK && w.Body.String() != test.body {
+			t.Error(test.path, " should contain ", test.body, " instead of ", w.Body.String())
+		}
+	}
+}
+
+// brokenAlteration renames .ext files into .out files,
+// but the content of the returned resource can not be dumped.
+type brokenAlteration struct{}
+
+func (ba brokenAlteration) Rename(name string) string {
+	return resource.ReplaceExt(name, ".ext", ".out")
+}
+
+func (ba brokenAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	return resource.NewAlteredResource(r, failingAlteration{}), nil
+}
+
+type failingAlteration struct{}
+
+func (fa failingAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	return &resource.Empty{}, errors.New("syntax error")
+}
+
+func TestManagerDevHandlerErrors(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Dev = true
+	m.Assets["pack"] = AssetPack{Input: "dirIn", Output: "dirOut", Alterations: []resource.Alteration{brokenAlteration{}}}
+	m.Assets["icons"] = Sprite{Input: "missing", Output: "dirOut/icons.png"}
+	h := m.DevHandler()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/static/dirOut/subDir/a2.out", nil))
+	if w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "syntax error") {
+		t.Error("the error of a compiled file should be served instead of ", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/static/dirOut/a1", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "pack-a1" {
+		t.Error("the sprite should not handle the other files of its directory: ", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/static/dirOut/icons.png", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Error("the error of the sprite should be served instead of ", w.Code)
+	}
+}
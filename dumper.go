@@ -1,39 +1,321 @@
 package statix
 
 import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+// DumpStatus describes what a Dumper did when it dumped a file.
+type DumpStatus int
+
+const (
+	// Created means that the symlink did not exist before the dump.
+	Created DumpStatus = iota
+	// Unchanged means that the file already existed with the same content
+	// and that the symlink was already pointing to it. Nothing was written.
+	Unchanged
+	// Updated means that the symlink existed but had to be updated.
+	Updated
 )
 
+// String returns the name of the DumpStatus.
+func (s DumpStatus) String() string {
+	switch s {
+	case Created:
+		return "created"
+	case Unchanged:
+		return "unchanged"
+	case Updated:
+		return "updated"
+	}
+	return "unknown"
+}
+
 // Dumper is the interface to dump asset content.
 type Dumper interface {
-	Dump(string, string, []byte) error
+	Dump(string, string, []byte) (DumpStatus, error)
+}
+
+// StreamDumper is implemented by the Dumpers that can dump
+// the content of a reader without loading it in memory.
+// DumpReader works like Dumper.Dump, but the filename is only known
+// once the content has been read. It is returned by the function
+// given as first parameter, from the md5 hash of the content.
+// DumpReader returns the filename in addition to the status.
+type StreamDumper interface {
+	DumpReader(func(string) (string, error), string, io.Reader) (string, DumpStatus, error)
+}
+
+// Unlinker is implemented by the Dumpers that can remove a symlink
+// created by a previous dump. Unlink removes the symlink if it exists,
+// the file it points to is kept.
+type Unlinker interface {
+	Unlink(string) error
+}
+
+// unlink removes `symlink` if the `dumper` implements the Unlinker interface.
+func unlink(dumper Dumper, symlink string) error {
+	if u, ok := dumper.(Unlinker); ok {
+		return u.Unlink(symlink)
+	}
+	return nil
+}
+
+// Sizer is implemented by the Dumpers that can tell if a file has already
+// been dumped. Size returns the size of the file `filename`, or -1 if it is
+// unknown, and false if the file does not exist.
+type Sizer interface {
+	Size(string) (int64, bool)
+}
+
+// dumpedSize calls the Size method of the `dumper` if it implements
+// the Sizer interface. Otherwise the file is considered missing.
+func dumpedSize(dumper Dumper, filename string) (int64, bool) {
+	if s, ok := dumper.(Sizer); ok {
+		return s.Size(filename)
+	}
+	return -1, false
+}
+
+// defaultDumper returns `d`, or a FileDumper if `d` is nil.
+func defaultDumper(d Dumper) Dumper {
+	if d == nil {
+		return FileDumper{}
+	}
+	return d
 }
 
 // FileDumper implements the Dumper interface to dump assets into files.
+// It also implements the StreamDumper, Unlinker and Sizer interfaces.
 type FileDumper struct{}
 
 // Dump write `data` in a file named `filename`
 // and create a symlink named `symlink` to that file.
 // If needed, directories will be created.
-func (fd FileDumper) Dump(filename, symlink string, data []byte) error {
+// The file and the symlink are replaced atomically, so the directory
+// can be served while the assets are dumped.
+// If `filename` already contains `data`, it is not written again.
+// If `symlink` already points to `filename`, it is not recreated.
+// If `symlink` is empty, only the file is written and the returned
+// status describes the file instead of the symlink.
+func (fd FileDumper) Dump(filename, symlink string, data []byte) (DumpStatus, error) {
 	err := os.MkdirAll(filepath.Dir(filename), 0755)
 	if err != nil {
-		return err
+		return Created, err
+	}
+
+	sameContent := hasContent(filename, data)
+	status := dumpStatus(filename, symlink, sameContent)
+
+	if !sameContent {
+		err = helpers.WriteFile(filename, data, 0644)
+		if err != nil {
+			return status, err
+		}
+	}
+
+	return fd.link(filename, symlink, status)
+}
+
+// DumpReader works like Dump but the content is read from `r`.
+// The content is copied in a temporary file while its md5 hash is computed.
+// The temporary file is then renamed to the filename returned by `name`
+// from this hash, unless this file already has the same content.
+// The temporary file is created in the directory of the final file,
+// given by `name` called with an empty hash, so the rename
+// never crosses file systems.
+func (fd FileDumper) DumpReader(name func(string) (string, error), symlink string, r io.Reader) (string, DumpStatus, error) {
+	probe, err := name("")
+	if err != nil {
+		return "", Created, err
+	}
+
+	dir := filepath.Dir(probe)
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return "", Created, err
+	}
+
+	h := md5.New()
+
+	tmp, err := helpers.WriteTempFile(dir, io.TeeReader(r, h))
+	if err != nil {
+		return "", Created, err
+	}
+	defer os.Remove(tmp)
+
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	filename, err := name(hash)
+	if err != nil {
+		return "", Created, err
+	}
+
+	info, err := os.Stat(tmp)
+	if err != nil {
+		return filename, Created, err
+	}
+
+	sameContent := hasHash(filename, info.Size(), hash)
+	status := dumpStatus(filename, symlink, sameContent)
+
+	if !sameContent {
+		err = os.MkdirAll(filepath.Dir(filename), 0755)
+		if err == nil {
+			err = os.Chmod(tmp, 0644)
+		}
+		if err == nil {
+			err = os.Rename(tmp, filename)
+		}
+		if err != nil {
+			return filename, status, err
+		}
+	}
+
+	status, err = fd.link(filename, symlink, status)
+	return filename, status, err
+}
+
+// Size returns the size of the regular file `filename`, and false if it does not exist.
+func (fd FileDumper) Size(filename string) (int64, bool) {
+	return regularFileSize(filename)
+}
+
+// regularFileSize returns the size of the regular file `filename`,
+// and false if it does not exist.
+func regularFileSize(filename string) (int64, bool) {
+	info, err := os.Stat(filename)
+	if err != nil || !info.Mode().IsRegular() {
+		return -1, false
+	}
+	return info.Size(), true
+}
+
+// Unlink removes `symlink` if it exists.
+// Nothing is removed if `symlink` is not a symlink.
+func (fd FileDumper) Unlink(symlink string) error {
+	info, err := os.Lstat(symlink)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+	return os.Remove(symlink)
+}
+
+// link creates `symlink` if needed.
+func (fd FileDumper) link(filename, symlink string, status DumpStatus) (DumpStatus, error) {
+	if symlink == "" || status == Unchanged {
+		return status, nil
+	}
+
+	err := helpers.Symlink(filename, symlink)
+	if err != nil {
+		return status, err
+	}
+
+	return status, nil
+}
+
+// dumpStatus returns the status of a dump, based on the current
+// state of `filename` and `symlink`. The `sameContent` parameter tells
+// if `filename` already contains the content that should be dumped.
+func dumpStatus(filename, symlink string, sameContent bool) DumpStatus {
+	if symlink == "" {
+		if sameContent {
+			return Unchanged
+		}
+		if _, err := os.Lstat(filename); err != nil {
+			return Created
+		}
+		return Updated
 	}
 
-	err = ioutil.WriteFile(filename, data, 0644)
+	target, err := os.Readlink(symlink)
 	if err != nil {
-		return err
+		return Created
+	}
+	if sameContent && target == filename {
+		return Unchanged
 	}
+	return Updated
+}
 
-	os.Remove(symlink)
+// hasContent checks if the file named `filename` exists and contains `data`.
+func hasContent(filename string, data []byte) bool {
+	info, err := os.Stat(filename)
+	if err != nil || !info.Mode().IsRegular() || info.Size() != int64(len(data)) {
+		return false
+	}
 
-	err = os.Symlink(filename, symlink)
+	content, err := ioutil.ReadFile(filename)
 	if err != nil {
-		return err
+		return false
 	}
 
+	return bytes.Equal(content, data)
+}
+
+// hasHash checks if the file named `filename` exists
+// and if its size and its md5 hash are the expected ones.
+func hasHash(filename string, size int64, hash string) bool {
+	info, err := os.Stat(filename)
+	if err != nil || !info.Mode().IsRegular() || info.Size() != size {
+		return false
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	fileHash, err := helpers.MD5Reader(f)
+
+	return err == nil && fileHash == hash
+}
+
+// PlanDumper implements the Dumper, StreamDumper, Unlinker and Sizer interfaces
+// but does not write anything. It returns the status that FileDumper
+// would return for the same call. It can be used to know what a dump would do.
+type PlanDumper struct{}
+
+// Dump returns the status that FileDumper.Dump would return
+// without writing `filename` nor `symlink`.
+func (pd PlanDumper) Dump(filename, symlink string, data []byte) (DumpStatus, error) {
+	return dumpStatus(filename, symlink, hasContent(filename, data)), nil
+}
+
+// DumpReader returns the filename and the status that FileDumper.DumpReader
+// would return without writing the file nor `symlink`.
+func (pd PlanDumper) DumpReader(name func(string) (string, error), symlink string, r io.Reader) (string, DumpStatus, error) {
+	h := md5.New()
+
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return "", Created, err
+	}
+
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	filename, err := name(hash)
+	if err != nil {
+		return "", Created, err
+	}
+
+	return filename, dumpStatus(filename, symlink, hasHash(filename, size, hash)), nil
+}
+
+// Unlink does nothing, PlanDumper does not write anything.
+func (pd PlanDumper) Unlink(symlink string) error {
 	return nil
 }
+
+// Size returns the size of the regular file `filename`, like FileDumper.Size.
+func (pd PlanDumper) Size(filename string) (int64, bool) {
+	return regularFileSize(filename)
+}
@@ -0,0 +1,199 @@
+package statix
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+func TestFileDumperDump(t *testing.T) {
+	removeTestFiles()
+	defer removeTestFiles()
+
+	fd := FileDumper{}
+	dir, _ := filepath.Abs("./tests/out")
+
+	status, err := fd.Dump(dir+"/file.1.ext", dir+"/file.ext", []byte("1"))
+	if err != nil {
+		t.Error(err)
+	}
+	if status != Created {
+		t.Error("status should be created instead of ", status)
+	}
+
+	content, err := ioutil.ReadFile(dir + "/file.ext")
+	if err != nil || !bytes.Equal(content, []byte("1")) {
+		t.Error("symlink should point to a file containing 1")
+	}
+
+	status, err = fd.Dump(dir+"/file.2.ext", dir+"/file.ext", []byte("2"))
+	if err != nil {
+		t.Error(err)
+	}
+	if status != Updated {
+		t.Error("status should be updated instead of ", status)
+	}
+
+	content, err = ioutil.ReadFile(dir + "/file.ext")
+	if err != nil || !bytes.Equal(content, []byte("2")) {
+		t.Error("symlink should point to a file containing 2")
+	}
+}
+
+func TestFileDumperDumpUnchanged(t *testing.T) {
+	removeTestFiles()
+	defer removeTestFiles()
+
+	fd := FileDumper{}
+	dir, _ := filepath.Abs("./tests/out")
+
+	fd.Dump(dir+"/file.1.ext", dir+"/file.ext", []byte("1"))
+
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(dir+"/file.1.ext", past, past)
+
+	status, err := fd.Dump(dir+"/file.1.ext", dir+"/file.ext", []byte("1"))
+	if err != nil {
+		t.Error(err)
+	}
+	if status != Unchanged {
+		t.Error("status should be unchanged instead of ", status)
+	}
+
+	info, err := os.Stat(dir + "/file.1.ext")
+	if err != nil || !info.ModTime().Equal(past) {
+		t.Error("unchanged file should not be written again")
+	}
+}
+
+func TestPlanDumperDump(t *testing.T) {
+	removeTestFiles()
+	defer removeTestFiles()
+
+	pd := PlanDumper{}
+	dir, _ := filepath.Abs("./tests/out")
+
+	status, err := pd.Dump(dir+"/file.1.ext", dir+"/file.ext", []byte("1"))
+	if err != nil || status != Created {
+		t.Error("status should be created instead of ", status)
+	}
+	if _, err := os.Lstat(dir + "/file.ext"); err == nil {
+		t.Error("PlanDumper should not write anything")
+	}
+
+	FileDumper{}.Dump(dir+"/file.1.ext", dir+"/file.ext", []byte("1"))
+
+	status, _ = pd.Dump(dir+"/file.1.ext", dir+"/file.ext", []byte("1"))
+	if status != Unchanged {
+		t.Error("status should be unchanged instead of ", status)
+	}
+
+	status, _ = pd.Dump(dir+"/file.2.ext", dir+"/file.ext", []byte("2"))
+	if status != Updated {
+		t.Error("status should be updated instead of ", status)
+	}
+	if _, err := os.Lstat(dir + "/file.2.ext"); err == nil {
+		t.Error("PlanDumper should not write anything")
+	}
+}
+
+func TestFileDumperDumpReader(t *testing.T) {
+	removeTestFiles()
+	defer removeTestFiles()
+
+	fd := FileDumper{}
+	dir, _ := filepath.Abs("./tests/out")
+
+	name := func(hash string) (string, error) {
+		return dir + "/file." + hash + ".ext", nil
+	}
+	hash1 := helpers.MD5([]byte("1"))
+
+	filename, status, err := fd.DumpReader(name, dir+"/file.ext", bytes.NewReader([]byte("1")))
+	if err != nil || status != Created || filename != dir+"/file."+hash1+".ext" {
+		t.Error("status should be created instead of ", filename, status, err)
+	}
+
+	content, err := ioutil.ReadFile(dir + "/file.ext")
+	if err != nil || !bytes.Equal(content, []byte("1")) {
+		t.Error("symlink should point to a file containing 1")
+	}
+
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(filename, past, past)
+
+	_, status, err = fd.DumpReader(name, dir+"/file.ext", bytes.NewReader([]byte("1")))
+	if err != nil || status != Unchanged {
+		t.Error("status should be unchanged instead of ", status, err)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil || !info.ModTime().Equal(past) {
+		t.Error("unchanged file should not be written again")
+	}
+
+	_, status, err = PlanDumper{}.DumpReader(name, dir+"/file.ext", bytes.NewReader([]byte("2")))
+	if err != nil || status != Updated {
+		t.Error("planned status should be updated instead of ", status, err)
+	}
+
+	_, status, err = fd.DumpReader(name, dir+"/file.ext", bytes.NewReader([]byte("2")))
+	if err != nil || status != Updated {
+		t.Error("status should be updated instead of ", status, err)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 3 {
+		t.Error("temporary files should not remain in the output directory")
+	}
+
+	_, _, err = fd.DumpReader(func(string) (string, error) {
+		return "", errors.New("name error")
+	}, dir+"/file.ext", bytes.NewReader([]byte("3")))
+	if err == nil {
+		t.Error("the error of the name function should be returned")
+	}
+
+	files, _ = ioutil.ReadDir(dir)
+	if len(files) != 3 {
+		t.Error("temporary files should be removed on error")
+	}
+}
+
+func TestFileDumperDumpReaderWithoutSymlink(t *testing.T) {
+	removeTestFiles()
+	defer removeTestFiles()
+
+	fd := FileDumper{}
+	dir, _ := filepath.Abs("./tests/out/sub")
+
+	name := func(hash string) (string, error) {
+		return dir + "/file." + hash + ".ext", nil
+	}
+
+	filename, status, err := fd.DumpReader(name, "", bytes.NewReader([]byte("1")))
+	if err != nil || status != Created || filename != dir+"/file."+helpers.MD5([]byte("1"))+".ext" {
+		t.Fatal("the file should be created instead of ", filename, status, err)
+	}
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil || !bytes.Equal(content, []byte("1")) {
+		t.Error("the file should contain 1")
+	}
+
+	_, status, err = fd.DumpReader(name, "", bytes.NewReader([]byte("1")))
+	if err != nil || status != Unchanged {
+		t.Error("status should be unchanged instead of ", status, err)
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Error("the temporary file should be created and removed in the directory of the file")
+	}
+}
@@ -0,0 +1,178 @@
+package statix
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// GenerateGo returns the source of a Go file in the package `pkg`
+// declaring a constant for the name of each asset and for the path
+// of each file of the AssetPacks and of the Sprites. Using these constants instead of strings
+// in Manager.URL allows the compiler to detect typos.
+//
+// If `manifest` is not nil, a constant with the url of each dumped file
+// is also declared. The urls are computed from the files of the Manifest.
+//
+// The names of the constants are the names of the assets and the paths
+// converted to CamelCase, for example "app-js" gives AppJs.
+func (m Manager) GenerateGo(pkg string, manifest Manifest) ([]byte, error) {
+	input, err := filepath.Abs(m.Input)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := filepath.Abs(m.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for name := range m.Assets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	g := &goGenerator{idents: map[string]string{}}
+
+	for _, name := range names {
+		g.add(&g.names, goIdent(name), name)
+
+		paths := []string{""}
+
+		switch a := m.Assets[name].(type) {
+		case AssetPack:
+			paths, err = a.RewritePaths(input, output).(AssetPack).OutputPaths()
+			if err != nil {
+				return nil, err
+			}
+			sort.Strings(paths)
+		case Sprite:
+			paths = a.Paths()
+		}
+
+		for _, path := range paths {
+			ident := goIdent(name) + goIdent(path)
+			if path != "" {
+				g.add(&g.paths, ident, path)
+			}
+			if manifest == nil {
+				continue
+			}
+
+			url, err := m.manifestURL(manifest, output, name, path)
+			if err != nil {
+				return nil, err
+			}
+			g.add(&g.urls, ident+"URL", url)
+		}
+	}
+
+	if g.err != nil {
+		return nil, g.err
+	}
+
+	return g.source(pkg)
+}
+
+// manifestURL returns the url of the file of an asset found in the `manifest`.
+func (m Manager) manifestURL(manifest Manifest, output, name, path string) (string, error) {
+	symlink, err := m.Symlink(name, path)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := relativePath(output, symlink)
+	if err != nil {
+		return "", err
+	}
+
+	entry, ok := manifest[key]
+	if !ok {
+		return "", fmt.Errorf("`%s` is not in the manifest", key)
+	}
+
+	return m.URLFromFilename(filepath.Join(output, filepath.FromSlash(entry.File)))
+}
+
+// goConst is a constant declared by GenerateGo.
+type goConst struct {
+	ident string
+	value string
+}
+
+// goGenerator collects the constants declared by GenerateGo.
+type goGenerator struct {
+	names  []goConst
+	paths  []goConst
+	urls   []goConst
+	idents map[string]string
+	err    error
+}
+
+// add appends a constant to `consts`. Two different values
+// can not have the same identifier.
+func (g *goGenerator) add(consts *[]goConst, ident, value string) {
+	if previous, ok := g.idents[ident]; ok && g.err == nil {
+		g.err = fmt.Errorf("`%s` and `%s` give the same identifier %s", previous, value, ident)
+		return
+	}
+	g.idents[ident] = value
+	*consts = append(*consts, goConst{ident: ident, value: value})
+}
+
+// source returns the formatted Go source.
+func (g *goGenerator) source(pkg string) ([]byte, error) {
+	buf := bytes.NewBuffer(nil)
+
+	buf.WriteString("// Code generated by statix. DO NOT EDIT.\n\n")
+	buf.WriteString("package " + pkg + "\n")
+
+	writeConsts(buf, "Names of the assets.", g.names)
+	writeConsts(buf, "Paths of the files of the asset packs.", g.paths)
+	writeConsts(buf, "Urls of the dumped files.", g.urls)
+
+	return format.Source(buf.Bytes())
+}
+
+func writeConsts(buf *bytes.Buffer, comment string, consts []goConst) {
+	if len(consts) == 0 {
+		return
+	}
+
+	buf.WriteString("\n// " + comment + "\nconst (\n")
+	for _, c := range consts {
+		buf.WriteString(c.ident + " = " + strconv.Quote(c.value) + "\n")
+	}
+	buf.WriteString(")\n")
+}
+
+// goIdent converts `s` into an exported Go identifier.
+// The letters and the digits are kept and the first letter
+// following any other character is upper cased.
+func goIdent(s string) string {
+	var b strings.Builder
+	upper := true
+
+	for _, r := range s {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if b.Len() == 0 && unicode.IsDigit(r) {
+			b.WriteString("Asset")
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
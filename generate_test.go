@@ -0,0 +1,75 @@
+package statix
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateGo(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+
+	src, err := m.GenerateGo("assets", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `// Code generated by statix. DO NOT EDIT.
+
+package assets
+
+// Names of the assets.
+const (
+	Pack   = "pack"
+	Single = "single"
+)
+
+// Paths of the files of the asset packs.
+const (
+	PackA1          = "a1"
+	PackSubDirA2Ext = "subDir/a2.ext"
+)
+`
+	if string(src) != expected {
+		t.Error("wrong generated code:\n", string(src))
+	}
+
+	report, _ := m.Dump()
+	output, _ := filepath.Abs(m.Output)
+	manifest, _ := NewManifest(output, report)
+
+	src, err = m.GenerateGo("assets", manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), `SingleURL          = "http://www.example.com/static/single.b34c31dcb721861cd51bfa6f3d850524.ext"`) {
+		t.Error("the urls from the manifest should be generated:\n", string(src))
+	}
+
+	if _, err = m.GenerateGo("assets", Manifest{}); err == nil {
+		t.Error("files missing from the manifest should return an error")
+	}
+}
+
+func TestGoIdent(t *testing.T) {
+	tests := map[string]string{
+		"app-js":          "AppJs",
+		"header/logo.png": "HeaderLogoPng",
+		"main_css":        "MainCss",
+		"3d":              "Asset3d",
+	}
+	for s, expected := range tests {
+		if ident := goIdent(s); ident != expected {
+			t.Error("wrong identifier for ", s, ": ", ident)
+		}
+	}
+
+	m := Manager{Assets: map[string]Asset{"a-b": SingleAsset{}, "a_b": SingleAsset{}}}
+	if _, err := m.GenerateGo("assets", nil); err == nil {
+		t.Error("identifier conflicts should return an error")
+	}
+}
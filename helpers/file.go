@@ -4,10 +4,17 @@ import (
 	"bytes"
 	"crypto/md5"
 	"encoding/hex"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 )
 
+// tmpPrefix is the prefix of the temporary files created
+// next to the files written by WriteFile and Symlink.
+const tmpPrefix = ".statix_tmp_"
+
 // RewritePath rewrites `currentPath`.
 // If `currentPath` is absolute, it does nothing but clean it.
 // But if `currentPath` is relative, it is prefixed by `basePath`.
@@ -48,3 +55,97 @@ func MD5(content []byte) string {
 	h.Write(content)
 	return hex.EncodeToString(h.Sum(nil))
 }
+
+// MD5Reader returns the md5 hash of the content of a reader.
+// The content is read by chunks, so it does not need to fit in memory.
+func MD5Reader(r io.Reader) (string, error) {
+	h := md5.New()
+	_, err := io.Copy(h, r)
+	return hex.EncodeToString(h.Sum(nil)), err
+}
+
+// WriteFile writes data in a temporary file located in the directory of `filename`
+// and then renames it to `filename`. The rename is atomic, so `filename`
+// is never partially written, even if the process dies during the write.
+func WriteFile(filename string, data []byte, perm os.FileMode) error {
+	tmp, err := WriteTempFile(filepath.Dir(filename), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(tmp, perm)
+	if err == nil {
+		err = os.Rename(tmp, filename)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+
+	return err
+}
+
+// WriteTempFile copies the content of `r` in a new temporary file
+// located in the directory `dir` and returns its name.
+// The content is copied by chunks, so it does not need to fit in memory.
+// The file can then be renamed atomically with os.Rename.
+// It is the caller's responsibility to remove the file if it is not renamed.
+func WriteTempFile(dir string, r io.Reader) (string, error) {
+	f, err := TempFile(dir, tmpPrefix, "")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(f, r)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// Symlink creates a symlink named `symlink` pointing to `target`.
+// If `symlink` already exists, it is replaced atomically
+// by renaming a temporary symlink, so `symlink` never stops existing.
+func Symlink(target, symlink string) error {
+	tmp, err := TempSymlink(filepath.Dir(symlink), tmpPrefix, target)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp, symlink)
+	if err != nil {
+		os.Remove(tmp)
+	}
+
+	return err
+}
+
+// RewriteFSPath is the equivalent of RewritePath for the paths inside an fs.FS.
+// These paths use slashes as separators. A path starting with a slash is absolute
+// and is relative to the root of the fs.FS. If `currentPath` is absolute,
+// it is only cleaned. Otherwise it is prefixed by `basePath`, but only if
+// `basePath` is a path inside the fs.FS. Absolute paths of the host file system
+// are not paths inside an fs.FS, so they are ignored.
+func RewriteFSPath(basePath, currentPath string) string {
+	if basePath == "" || filepath.IsAbs(basePath) || path.IsAbs(currentPath) {
+		return path.Clean(currentPath)
+	}
+	return path.Clean(filepath.ToSlash(basePath) + "/" + currentPath)
+}
+
+// FSPath converts a path returned by RewriteFSPath
+// into a name that can be given to the methods of an fs.FS.
+func FSPath(p string) string {
+	p = strings.TrimPrefix(path.Clean("/"+p), "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
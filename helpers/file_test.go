@@ -1,6 +1,12 @@
 package helpers
 
-import "testing"
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
 
 func TestRewritePath(t *testing.T) {
 	if RewritePath("test", "/absolute") != "/absolute" {
@@ -43,3 +49,91 @@ func TestMD5(t *testing.T) {
 		t.Error("md5 should return ef8efa55f449e3727c4df433ce7744c5")
 	}
 }
+
+func TestWriteFile(t *testing.T) {
+	dir, _ := TempDir("", "statix_test_", "")
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "file")
+
+	for _, content := range []string{"first", "second"} {
+		if err := WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Error(err)
+		}
+		c, err := ioutil.ReadFile(filename)
+		if err != nil || string(c) != content {
+			t.Error("file should contain ", content, " instead of ", string(c))
+		}
+	}
+
+	info, _ := os.Stat(filename)
+	if info.Mode().Perm() != 0644 {
+		t.Error("file should have 0644 permissions instead of ", info.Mode().Perm())
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Error("temporary files should not remain in the directory")
+	}
+}
+
+func TestSymlink(t *testing.T) {
+	dir, _ := TempDir("", "statix_test_", "")
+	defer os.RemoveAll(dir)
+
+	symlink := filepath.Join(dir, "link")
+
+	for _, target := range []string{"target1", "target2"} {
+		if err := Symlink(filepath.Join(dir, target), symlink); err != nil {
+			t.Error(err)
+		}
+		link, err := os.Readlink(symlink)
+		if err != nil || link != filepath.Join(dir, target) {
+			t.Error("symlink should point to ", target, " instead of ", link)
+		}
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Error("temporary symlinks should not remain in the directory")
+	}
+}
+
+func TestMD5Reader(t *testing.T) {
+	hash, err := MD5Reader(bytes.NewReader([]byte("testMd5")))
+	if err != nil || hash != "ef8efa55f449e3727c4df433ce7744c5" {
+		t.Error("md5 should return ef8efa55f449e3727c4df433ce7744c5")
+	}
+}
+
+func TestRewriteFSPath(t *testing.T) {
+	if RewriteFSPath("base", "/absolute") != "/absolute" {
+		t.Error("absolute paths should not be modified")
+	}
+
+	if RewriteFSPath("base", "relative//path/") != "base/relative/path" {
+		t.Error("basePath should be added to relative paths")
+	}
+
+	if RewriteFSPath("/host/directory", "relative") != "relative" {
+		t.Error("absolute host paths should be ignored")
+	}
+
+	if RewriteFSPath("", "relative") != "relative" {
+		t.Error("empty basePath should be ignored")
+	}
+}
+
+func TestFSPath(t *testing.T) {
+	if FSPath("/absolute/path") != "absolute/path" {
+		t.Error("leading slash should be removed")
+	}
+
+	if FSPath("") != "." || FSPath("/") != "." {
+		t.Error("root should be .")
+	}
+
+	if FSPath("a/../b") != "b" {
+		t.Error("path should be cleaned")
+	}
+}
@@ -99,3 +99,33 @@ func TempDir(dir, prefix, suffix string) (name string, err error) {
 	}
 	return
 }
+
+// TempSymlink creates a new symbolic link pointing to target in the directory dir
+// with a name beginning with prefix and returns the path of the new link.
+// If dir is the empty string, TempSymlink uses the default directory
+// for temporary files (see os.TempDir).
+// It is the caller's responsibility to remove the link when no longer needed.
+func TempSymlink(dir, prefix, target string) (name string, err error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	nconflict := 0
+	for i := 0; i < 10000; i++ {
+		try := filepath.Join(dir, prefix+nextSuffix())
+		err = os.Symlink(target, try)
+		if os.IsExist(err) {
+			if nconflict++; nconflict > 10 {
+				randmu.Lock()
+				rand = reseed()
+				randmu.Unlock()
+			}
+			continue
+		}
+		if err == nil {
+			name = try
+		}
+		break
+	}
+	return
+}
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/url"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/sarulabs/statix/helpers"
 	"github.com/sarulabs/statix/resource"
@@ -35,38 +37,141 @@ type Filter struct {
 //     url of a given asset, this attribute will be used.
 // - Manager.Servers is used like Manager.Server. Use it if your assets are in different directories.
 // - Filters contains a list of filters that will be applied to all your assets before dumping them.
+// - Compressions contains a list of compressions used to write compressed siblings
+//     (for example .gz or .br files) next to the dumped files.
+// - Manifest is the name of the json file in which the Manifest is written
+//     after each dump. If it is relative, it is based in Manager.Output.
+//     If it is empty, no manifest is written.
+// - Dumper is used to dump all the assets and the manifest. If it is nil,
+//     each asset uses its own Dumper and the manifest is written with a FileDumper.
+// - Dev enables the development mode. Manager.URL returns urls to the files
+//     without the md5 suffix, that can be served by Manager.DevHandler without dumping the assets.
 // - Assets contains all your assets. The key of the map is the name of the asset.
 type Manager struct {
-	Input   string
-	Output  string
-	Server  Server
-	Servers []Server
-	Filters []Filter
-	Assets  map[string]Asset
+	Input        string
+	Output       string
+	Server       Server
+	Servers      []Server
+	Filters      []Filter
+	Compressions []Compression
+	Manifest     string
+	Dumper       Dumper
+	Dev          bool
+	Assets       map[string]Asset
 }
 
 // Dump dumps all defined assets.
 // If an asset path is relative, it is rewritten to be based
 // in manager.Input and Manager.Output.
-func (m Manager) Dump() error {
+// The returned DumpReport lists the files that have been created, updated
+// or left unchanged because they were already up to date.
+// If Manager.Manifest is defined, the Manifest is written after all the assets.
+func (m Manager) Dump() (DumpReport, error) {
+	return m.dump(nil)
+}
+
+// DumpStats dumps all the assets like Manager.Dump,
+// and also returns the DumpStats of the dump: the duration of the dump of each asset,
+// the sizes of the dumped files and the time spent in each alteration.
+func (m Manager) DumpStats() (DumpReport, DumpStats, error) {
+	timer := newAlterationTimer()
+	stats := &DumpStats{}
+
+	start := time.Now()
+	report, err := timer.manager(m).dump(stats)
+	stats.Duration = time.Since(start)
+	stats.Alterations = timer.stats()
+
+	return report, *stats, err
+}
+
+// dump dumps all the assets in the order of their names.
+// If `stats` is not nil, the AssetStats of the dumped assets are added to it.
+func (m Manager) dump(stats *DumpStats) (DumpReport, error) {
+	report := DumpReport{}
+
 	input, err := filepath.Abs(m.Input)
 	if err != nil {
-		return err
+		return report, err
 	}
 
 	output, err := filepath.Abs(m.Output)
 	if err != nil {
-		return err
+		return report, err
+	}
+
+	if stats != nil {
+		stats.Output = output
+	}
+
+	names := make([]string, 0, len(m.Assets))
+	for name := range m.Assets {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	for _, a := range m.Assets {
-		err = a.RewritePaths(input, output).Dump(m.Filters)
+	for _, name := range names {
+		start := time.Now()
+		r, err := m.rewriteAsset(m.Assets[name], input, output).Dump(m.Filters, m.Compressions)
+		report.Add(r)
+		if stats != nil {
+			stats.Assets = append(stats.Assets, AssetStats{
+				Name:     name,
+				Duration: time.Since(start),
+				Files:    r.Files,
+			})
+		}
 		if err != nil {
-			return err
+			return report, err
 		}
 	}
 
-	return nil
+	if m.Manifest == "" {
+		return report, nil
+	}
+
+	return report, m.dumpManifest(output, report)
+}
+
+// dumpManifest writes the Manifest built from the `report`
+// in the Manager.Manifest file.
+func (m Manager) dumpManifest(output string, report DumpReport) error {
+	manifest, err := NewManifest(output, report)
+	if err != nil {
+		return err
+	}
+
+	c, err := manifest.JSON()
+	if err != nil {
+		return err
+	}
+
+	_, err = defaultDumper(m.Dumper).Dump(helpers.RewritePath(output, m.Manifest), "", c)
+	return err
+}
+
+// rewriteAsset rewrites the paths of an asset with Asset.RewritePaths.
+// If Manager.Dumper is defined, it also replaces the Dumper of the asset.
+func (m Manager) rewriteAsset(a Asset, input, output string) Asset {
+	a = a.RewritePaths(input, output)
+
+	if m.Dumper == nil {
+		return a
+	}
+
+	switch a := a.(type) {
+	case AssetPack:
+		a.Dumper = m.Dumper
+		return a
+	case SingleAsset:
+		a.Dumper = m.Dumper
+		return a
+	case Sprite:
+		a.Dumper = m.Dumper
+		return a
+	}
+
+	return a
 }
 
 // URL returns the url of an asset thanks to its name
@@ -79,11 +184,21 @@ func (m Manager) Dump() error {
 // But if the asset is an AssetPack, you also need to give its path inside the output directory.
 // For example, manager.Url("pack", "/js/jquery.js") will look into the output directory
 // of the asset named "pack" for the file {outputDirectory}/js/jquery.js
+//
+// For a Sprite, the name gives the url of the image, and the paths SpriteCSS
+// and SpriteJSON give the urls of its stylesheet and of its json map.
+//
+// If Manager.Dev is true, the returned url is the url of the symlink.
+// It does not need the assets to be dumped and can be served by Manager.DevHandler.
 func (m Manager) URL(assetName string, paths ...string) string {
 	symlink, err := m.Symlink(assetName, paths...)
 	if err != nil {
 		return ""
 	}
+	if m.Dev {
+		url, _ := m.URLFromFilename(symlink)
+		return url
+	}
 	url, _ := m.URLFromSymlink(symlink)
 	return url
 }
@@ -91,24 +206,28 @@ func (m Manager) URL(assetName string, paths ...string) string {
 // Symlink returns the filename of an asset symlink.
 // For a SingleAsset, only the name of the asset is needed.
 // For AssetPack, you also need to provide the path of the file inside the output directory
+// For a Sprite, the path is empty for the image, or one of SpriteCSS and SpriteJSON.
 func (m Manager) Symlink(assetName string, paths ...string) (string, error) {
 	a, ok := m.Assets[assetName]
 	if !ok {
 		return "", fmt.Errorf("asset `%s` does not exist", assetName)
 	}
 
+	path := ""
+	if len(paths) > 0 {
+		path = paths[0]
+	}
+
 	switch a.(type) {
 	case AssetPack:
-		path := ""
-		if len(paths) > 0 {
-			path = paths[0]
-		}
 		return m.AssetPackSymlink(a.(AssetPack), path)
 	case SingleAsset:
 		return m.SingleAssetSymlink(a.(SingleAsset))
+	case Sprite:
+		return m.SpriteSymlink(a.(Sprite), path)
 	}
 
-	return "", errors.New("asset should be an AssetPack or a SingleAsset")
+	return "", errors.New("asset should be an AssetPack, a SingleAsset or a Sprite")
 }
 
 // AssetPackSymlink returns the filename of an asset symlink.
@@ -126,8 +245,25 @@ func (m Manager) SingleAssetSymlink(sa SingleAsset) (string, error) {
 	return sa.OutputFile("")
 }
 
+// SpriteSymlink returns the filename of the symlink of a file of a Sprite.
+// It does not check if the symlink exists.
+func (m Manager) SpriteSymlink(sp Sprite, path string) (string, error) {
+	sp = sp.RewritePaths(m.Input, m.Output).(Sprite)
+	return sp.OutputFile(path, "")
+}
+
 // URLFromSymlink returns the url of an asset given its symlink.
+// If Manager.Dumper implements the Resolver interface, it is used to find the file
+// the symlink points to. Otherwise the symlink is read with filepath.EvalSymlinks.
 func (m Manager) URLFromSymlink(symlink string) (string, error) {
+	if r, ok := m.Dumper.(Resolver); ok {
+		filename, err := r.Resolve(symlink)
+		if err != nil {
+			return "", err
+		}
+		return m.URLFromFilename(filename)
+	}
+
 	filename, err := filepath.EvalSymlinks(symlink)
 	if err != nil {
 		return "", err
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/sarulabs/statix/resource"
 )
@@ -67,7 +68,7 @@ func TestManagerDump(t *testing.T) {
 	defer removeTestFiles()
 
 	m := getManagerTest()
-	err := m.Dump()
+	_, err := m.Dump()
 
 	if err != nil {
 		t.Error(err)
@@ -189,3 +190,81 @@ func TestManagerURL(t *testing.T) {
 		t.Error("url should be ", expected, " instead of ", url)
 	}
 }
+
+func TestManagerDumpReport(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+
+	report, err := m.Dump()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(report.Created()) != 3 || len(report.Unchanged()) != 0 || len(report.Updated()) != 0 {
+		t.Error("all files should be created on the first dump")
+	}
+
+	// ReverseAlteration reverses the content of the single asset in place
+	m.Assets["single"] = getManagerTest().Assets["single"]
+
+	report, err = m.Dump()
+	if err != nil {
+		t.Error(err)
+	}
+	if len(report.Created()) != 0 || len(report.Unchanged()) != 3 || len(report.Updated()) != 0 {
+		t.Error("all files should be unchanged on the second dump")
+	}
+
+	ioutil.WriteFile("./tests/in/dirIn/a1", []byte("pack-a1-updated"), 0777)
+	m.Assets["single"] = getManagerTest().Assets["single"]
+
+	report, err = m.Dump()
+	if err != nil {
+		t.Error(err)
+	}
+	updated := report.Updated()
+	if len(updated) != 1 || !strings.HasSuffix(updated[0], "/out/dirOut/a1") {
+		t.Error("a1 should be updated instead of ", updated)
+	}
+}
+
+func TestManagerDumpFS(t *testing.T) {
+	removeTestFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Assets["pack"] = AssetPack{
+		Input:  "dirIn",
+		Output: "dirOut",
+		FS: fstest.MapFS{
+			"dirIn/a1":            {Data: []byte("pack-a1")},
+			"dirIn/subDir/a2.ext": {Data: []byte("pack-a2")},
+			"other/a3":            {Data: []byte("pack-a3")},
+		},
+	}
+
+	_, err := m.Dump()
+	if err != nil {
+		t.Error(err)
+	}
+
+	content, err := ioutil.ReadFile("./tests/out/dirOut/a1")
+	if err != nil || !bytes.Equal(content, []byte("pack-a1")) {
+		t.Error("a1 should contain pack-a1 instead of ", string(content))
+	}
+
+	content, err = ioutil.ReadFile("./tests/out/dirOut/subDir/a2.ext")
+	if err != nil || !bytes.Equal(content, []byte("2a-kcap")) {
+		t.Error("a2.ext should contain 2a-kcap instead of ", string(content))
+	}
+
+	if _, err = os.Stat("./tests/out/dirOut/a3"); err == nil {
+		t.Error("files outside of the input directory should not be dumped")
+	}
+
+	if url := m.URL("pack", "a1"); url != "http://www.example.com/static/dirOut/a1.df54fa5f220b244f5ed919c871fe56f0" {
+		t.Error("wrong url for a file of an AssetPack with an FS: ", url)
+	}
+}
@@ -0,0 +1,135 @@
+package statix
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// Manifest describes the dumped files. It maps the path of each symlink
+// to a ManifestEntry. All the paths in the Manifest are relative
+// to the output directory of the Manager and use slashes as separators.
+type Manifest map[string]ManifestEntry
+
+// ManifestEntry describes a dumped file.
+// File is the path of the file with the md5 suffix.
+// Compressed contains the paths of its compressed siblings.
+// The key of the map is the extension of the Compression that created it.
+// Alternatives contains the paths of the other formats of the file
+// (see Alternative). The key of the map is their content type.
+type ManifestEntry struct {
+	File         string            `json:"file"`
+	Compressed   map[string]string `json:"compressed,omitempty"`
+	Alternatives map[string]string `json:"alternatives,omitempty"`
+}
+
+// NewManifest creates a Manifest from a DumpReport.
+// The paths of the files in the report are made relative to the `output` directory.
+func NewManifest(output string, report DumpReport) (Manifest, error) {
+	manifest := Manifest{}
+
+	for _, f := range report.Files {
+		symlink, err := relativePath(output, f.Symlink)
+		if err != nil {
+			return manifest, err
+		}
+
+		file, err := relativePath(output, f.Filename)
+		if err != nil {
+			return manifest, err
+		}
+
+		entry := ManifestEntry{File: file}
+
+		for ext, filename := range f.Compressed {
+			compressed, err := relativePath(output, filename)
+			if err != nil {
+				return manifest, err
+			}
+			if entry.Compressed == nil {
+				entry.Compressed = map[string]string{}
+			}
+			entry.Compressed[ext] = compressed
+		}
+
+		for contentType, filename := range f.Alternatives {
+			alternative, err := relativePath(output, filename)
+			if err != nil {
+				return manifest, err
+			}
+			if entry.Alternatives == nil {
+				entry.Alternatives = map[string]string{}
+			}
+			entry.Alternatives[contentType] = alternative
+		}
+
+		manifest[symlink] = entry
+	}
+
+	return manifest, nil
+}
+
+// ReadManifest reads a Manifest from a json file.
+func ReadManifest(filename string) (Manifest, error) {
+	manifest := Manifest{}
+
+	c, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return manifest, err
+	}
+
+	err = json.Unmarshal(c, &manifest)
+	return manifest, err
+}
+
+// JSON returns the json representation of the Manifest.
+// The keys are sorted, so the same Manifest always gives the same output.
+func (m Manifest) JSON() ([]byte, error) {
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// Negotiate returns the path of the file that should be served for the `symlink`
+// to a client sending the `accept` header. The first content type of the header
+// that is one of the alternatives of the file is chosen. If there is none,
+// the path of the file itself is returned. Wildcards do not match the alternatives.
+// An empty string is returned if the `symlink` is not in the Manifest.
+func (m Manifest) Negotiate(symlink, accept string) string {
+	entry, ok := m[symlink]
+	if !ok {
+		return ""
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		contentType := strings.TrimSpace(params[0])
+
+		alternative, ok := entry.Alternatives[contentType]
+		if ok && !refused(params[1:]) {
+			return alternative
+		}
+	}
+
+	return entry.File
+}
+
+// refused checks if the parameters of a media type of an accept header contain q=0.
+func refused(params []string) bool {
+	for _, p := range params {
+		kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+		if len(kv) == 2 && kv[0] == "q" && strings.Trim(kv[1], "0.") == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// relativePath returns the path of `filename` relative to `dir`
+// with slashes as separators.
+func relativePath(dir, filename string) (string, error) {
+	rel, err := filepath.Rel(dir, filename)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(rel), nil
+}
@@ -0,0 +1,323 @@
+package statix
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+// Resolver is implemented by the Dumpers that can tell which file
+// a symlink points to. It is used by Manager.URLFromSymlink instead of
+// filepath.EvalSymlinks when Manager.Dumper implements it.
+type Resolver interface {
+	Resolve(string) (string, error)
+}
+
+// MemoryDumper implements the Dumper, StreamDumper, Unlinker, Sizer and Resolver interfaces.
+// It keeps the dumped files and the symlinks (named aliases) in memory
+// instead of writing them on the disk.
+//
+// The dumped files should be located in the MemoryDumper.Root directory.
+// MemoryDumper implements the fs.FS interface. The names given to Open
+// are the slash separated paths of the files and the aliases relative to the root.
+// MemoryDumper.FileSystem returns an http.FileSystem serving the same files.
+type MemoryDumper struct {
+	Root    string
+	mu      sync.RWMutex
+	files   map[string][]byte
+	aliases map[string]string
+}
+
+// NewMemoryDumper creates a MemoryDumper that can dump the files located in `root`.
+// It should be the output directory of the Manager.
+func NewMemoryDumper(root string) *MemoryDumper {
+	return &MemoryDumper{
+		Root:    root,
+		files:   map[string][]byte{},
+		aliases: map[string]string{},
+	}
+}
+
+// Dump stores `data` in memory as `filename`
+// and creates the alias `symlink` pointing to that file.
+// The returned status is computed like in FileDumper.Dump.
+// If `symlink` is empty, only the file is stored.
+func (md *MemoryDumper) Dump(filename, symlink string, data []byte) (DumpStatus, error) {
+	name, err := md.name(filename)
+	if err != nil {
+		return Created, err
+	}
+
+	alias := ""
+	if symlink != "" {
+		alias, err = md.name(symlink)
+		if err != nil {
+			return Created, err
+		}
+	}
+
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	if md.files == nil {
+		md.files = map[string][]byte{}
+		md.aliases = map[string]string{}
+	}
+
+	current, exists := md.files[name]
+	sameContent := exists && bytes.Equal(current, data)
+
+	status := Updated
+	if alias == "" {
+		if !exists {
+			status = Created
+		} else if sameContent {
+			status = Unchanged
+		}
+	} else if target, ok := md.aliases[alias]; !ok {
+		status = Created
+	} else if sameContent && target == name {
+		status = Unchanged
+	}
+
+	md.files[name] = append([]byte{}, data...)
+	if alias != "" {
+		md.aliases[alias] = name
+	}
+
+	return status, nil
+}
+
+// DumpReader works like Dump but the content is read from `r`.
+// The filename is returned by `name` from the md5 hash of the content.
+// The MemoryDumper keeps the files in memory, so the whole content is buffered.
+func (md *MemoryDumper) DumpReader(name func(string) (string, error), symlink string, r io.Reader) (string, DumpStatus, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return "", Created, err
+	}
+
+	filename, err := name(helpers.MD5(data))
+	if err != nil {
+		return "", Created, err
+	}
+
+	status, err := md.Dump(filename, symlink, data)
+	return filename, status, err
+}
+
+// Size returns the size of the file `filename`, and false if it has not been dumped.
+func (md *MemoryDumper) Size(filename string) (int64, bool) {
+	name, err := md.name(filename)
+	if err != nil {
+		return -1, false
+	}
+
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	data, ok := md.files[name]
+	if !ok {
+		return -1, false
+	}
+	return int64(len(data)), true
+}
+
+// Unlink removes the alias `symlink`. The file it points to is kept.
+func (md *MemoryDumper) Unlink(symlink string) error {
+	alias, err := md.name(symlink)
+	if err != nil {
+		return err
+	}
+
+	md.mu.Lock()
+	defer md.mu.Unlock()
+
+	delete(md.aliases, alias)
+
+	return nil
+}
+
+// Resolve returns the filename of the file `symlink` points to.
+func (md *MemoryDumper) Resolve(symlink string) (string, error) {
+	alias, err := md.name(symlink)
+	if err != nil {
+		return "", err
+	}
+
+	root, err := filepath.Abs(md.Root)
+	if err != nil {
+		return "", err
+	}
+
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	name, ok := md.aliases[alias]
+	if !ok {
+		return "", errors.New(symlink + " does not exist")
+	}
+
+	return filepath.Join(root, filepath.FromSlash(name)), nil
+}
+
+// name returns the path of `filename` relative to MemoryDumper.Root.
+func (md *MemoryDumper) name(filename string) (string, error) {
+	root, err := filepath.Abs(md.Root)
+	if err != nil {
+		return "", err
+	}
+
+	filename, err = filepath.Abs(filename)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(root, filename)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New(filename + " is not located in " + md.Root)
+	}
+
+	return filepath.ToSlash(rel), nil
+}
+
+// Open opens the file or the alias `name` of the MemoryDumper.
+// It implements the fs.FS interface.
+func (md *MemoryDumper) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	md.mu.RLock()
+	defer md.mu.RUnlock()
+
+	if target, ok := md.aliases[name]; ok {
+		if data, ok := md.files[target]; ok {
+			return &memoryFile{Reader: bytes.NewReader(data), info: memoryFileInfo{name: path.Base(name), size: int64(len(data))}}, nil
+		}
+	}
+
+	if data, ok := md.files[name]; ok {
+		return &memoryFile{Reader: bytes.NewReader(data), info: memoryFileInfo{name: path.Base(name), size: int64(len(data))}}, nil
+	}
+
+	entries := md.readDir(name)
+	if entries == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return &memoryDir{info: memoryFileInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+}
+
+// readDir returns the entries of the directory `dir`, sorted by name.
+// It returns nil if the directory does not exist.
+func (md *MemoryDumper) readDir(dir string) []fs.DirEntry {
+	prefix := dir + "/"
+	if dir == "." {
+		prefix = ""
+	}
+
+	infos := map[string]memoryFileInfo{}
+
+	add := func(name string, size int64) {
+		if !strings.HasPrefix(name, prefix) {
+			return
+		}
+		rest := name[len(prefix):]
+		if i := strings.Index(rest, "/"); i >= 0 {
+			infos[rest[:i]] = memoryFileInfo{name: rest[:i], dir: true}
+			return
+		}
+		infos[rest] = memoryFileInfo{name: rest, size: size}
+	}
+
+	for name, data := range md.files {
+		add(name, int64(len(data)))
+	}
+	for alias, target := range md.aliases {
+		add(alias, int64(len(md.files[target])))
+	}
+
+	if len(infos) == 0 && dir != "." {
+		return nil
+	}
+
+	entries := []fs.DirEntry{}
+	for _, info := range infos {
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	return entries
+}
+
+// FileSystem returns an http.FileSystem serving the files
+// and the aliases of the MemoryDumper. It can be used with http.FileServer.
+func (md *MemoryDumper) FileSystem() http.FileSystem {
+	return http.FS(md)
+}
+
+// memoryFileInfo implements fs.FileInfo for the files of a MemoryDumper.
+type memoryFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (fi memoryFileInfo) Name() string       { return fi.name }
+func (fi memoryFileInfo) Size() int64        { return fi.size }
+func (fi memoryFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi memoryFileInfo) IsDir() bool        { return fi.dir }
+func (fi memoryFileInfo) Sys() interface{}   { return nil }
+
+func (fi memoryFileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// memoryFile implements fs.File for the files of a MemoryDumper.
+type memoryFile struct {
+	*bytes.Reader
+	info memoryFileInfo
+}
+
+func (f *memoryFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *memoryFile) Close() error               { return nil }
+
+// memoryDir implements fs.ReadDirFile for the directories of a MemoryDumper.
+type memoryDir struct {
+	info    memoryFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+func (d *memoryDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *memoryDir) Close() error               { return nil }
+
+func (d *memoryDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: errors.New("is a directory")}
+}
+
+func (d *memoryDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	entries := d.entries[d.offset:]
+	if n > 0 && len(entries) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	d.offset += len(entries)
+	return entries, nil
+}
@@ -0,0 +1,123 @@
+package statix
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMemoryDumper(t *testing.T) {
+	md := NewMemoryDumper("/out")
+
+	status, err := md.Dump("/out/js/app.123.js", "/out/js/app.js", []byte("app"))
+	if err != nil || status != Created {
+		t.Error("first dump should create the alias: ", status, err)
+	}
+
+	status, err = md.Dump("/out/js/app.123.js", "/out/js/app.js", []byte("app"))
+	if err != nil || status != Unchanged {
+		t.Error("same dump should be unchanged: ", status, err)
+	}
+
+	status, err = md.Dump("/out/js/app.456.js", "/out/js/app.js", []byte("app2"))
+	if err != nil || status != Updated {
+		t.Error("new content should update the alias: ", status, err)
+	}
+
+	status, err = md.Dump("/out/manifest.json", "", []byte("{}"))
+	if err != nil || status != Created {
+		t.Error("file without alias should be created: ", status, err)
+	}
+
+	if _, err = md.Dump("/elsewhere/a.js", "", []byte("a")); err == nil {
+		t.Error("files outside of the root should not be dumped")
+	}
+
+	filename, err := md.Resolve("/out/js/app.js")
+	if err != nil || filename != "/out/js/app.456.js" {
+		t.Error("wrong resolved alias: ", filename, err)
+	}
+
+	if _, err = md.Resolve("/out/js/missing.js"); err == nil {
+		t.Error("missing alias should not be resolved")
+	}
+
+	err = fstest.TestFS(md, "js/app.js", "js/app.123.js", "js/app.456.js", "manifest.json")
+	if err != nil {
+		t.Error(err)
+	}
+
+	c, err := fs.ReadFile(md, "js/app.js")
+	if err != nil || string(c) != "app2" {
+		t.Error("alias should contain the content of its target: ", string(c), err)
+	}
+}
+
+func TestMemoryDumperFileSystem(t *testing.T) {
+	md := NewMemoryDumper("/out")
+	md.Dump("/out/css/main.123.css", "/out/css/main.css", []byte("body{}"))
+
+	w := httptest.NewRecorder()
+	http.FileServer(md.FileSystem()).ServeHTTP(w, httptest.NewRequest("GET", "/css/main.css", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "body{}" {
+		t.Error("wrong response from the http.FileSystem: ", w.Code, w.Body.String())
+	}
+}
+
+func TestManagerMemoryDumper(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	output, _ := filepath.Abs("./tests/out")
+	md := NewMemoryDumper(output)
+
+	m := getManagerTest()
+	m.Manifest = "manifest.json"
+	m.Dumper = md
+
+	_, err := m.Dump()
+	if err != nil {
+		t.Error(err)
+	}
+
+	if _, err = os.Stat("./tests/out"); err == nil {
+		t.Error("nothing should be written on the disk")
+	}
+
+	url := m.URL("pack", "subDir/a2.ext")
+	expected := "http://www.example.com/static/dirOut/subDir/a2.4ee925ce5ea7f2ce1d0fe37f273dff23.ext"
+	if url != expected {
+		t.Error("url should be ", expected, " instead of ", url)
+	}
+
+	c, err := fs.ReadFile(md, "dirOut/subDir/a2.ext")
+	if err != nil || string(c) != "2a-kcap" {
+		t.Error("wrong content in the MemoryDumper: ", string(c), err)
+	}
+
+	if _, err = fs.Stat(md, "manifest.json"); err != nil {
+		t.Error("the manifest should be dumped in memory")
+	}
+}
+
+func TestMemoryDumperUnlink(t *testing.T) {
+	md := NewMemoryDumper("./tests/out")
+
+	md.Dump("./tests/out/file.1.ext", "./tests/out/file.ext", []byte("1"))
+
+	if err := md.Unlink("./tests/out/file.ext"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := md.Resolve("./tests/out/file.ext"); err == nil {
+		t.Error("the alias should be removed")
+	}
+	if _, err := md.Open("file.1.ext"); err != nil {
+		t.Error("the file should be kept: ", err)
+	}
+}
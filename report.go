@@ -0,0 +1,83 @@
+package statix
+
+// DumpedFile describes a file handled by a Dumper.
+// Filename is the file containing the asset (with the md5 suffix)
+// and Symlink is the link pointing to it.
+// Compressed contains the compressed siblings of Filename.
+// The key of the map is the extension of the Compression.
+// Alternatives contains the files of the other formats of Filename
+// (see Alternative). The key of the map is their content type.
+// Size is the size of Filename in bytes and CompressedSizes contains
+// the sizes of the compressed siblings. InputSize is the size of the
+// content before the alterations and the filters, or -1 if it is unknown.
+type DumpedFile struct {
+	Filename        string
+	Symlink         string
+	Status          DumpStatus
+	Compressed      map[string]string
+	Alternatives    map[string]string
+	Size            int64
+	InputSize       int64
+	CompressedSizes map[string]int64
+}
+
+// Ratio returns the size of the DumpedFile divided by its InputSize,
+// or 0 if the InputSize is unknown or empty.
+func (f DumpedFile) Ratio() float64 {
+	return sizeRatio(f.Size, f.InputSize)
+}
+
+// CompressionRatio returns the size of the compressed sibling with the
+// extension `ext` divided by the size of the DumpedFile,
+// or 0 if there is no such sibling.
+func (f DumpedFile) CompressionRatio(ext string) float64 {
+	size, ok := f.CompressedSizes[ext]
+	if !ok {
+		return 0
+	}
+	return sizeRatio(size, f.Size)
+}
+
+// sizeRatio returns `size` divided by `of`, or 0 if `of` is not positive.
+func sizeRatio(size, of int64) float64 {
+	if of <= 0 {
+		return 0
+	}
+	return float64(size) / float64(of)
+}
+
+// DumpReport lists the files handled during a dump.
+type DumpReport struct {
+	Files []DumpedFile
+}
+
+// Add appends the files of an other report to the DumpReport.
+func (r *DumpReport) Add(other DumpReport) {
+	r.Files = append(r.Files, other.Files...)
+}
+
+// Created returns the symlinks of the files that did not exist before the dump.
+func (r DumpReport) Created() []string {
+	return r.symlinks(Created)
+}
+
+// Unchanged returns the symlinks of the files that were skipped
+// because they were already up to date.
+func (r DumpReport) Unchanged() []string {
+	return r.symlinks(Unchanged)
+}
+
+// Updated returns the symlinks of the files that have been updated.
+func (r DumpReport) Updated() []string {
+	return r.symlinks(Updated)
+}
+
+func (r DumpReport) symlinks(status DumpStatus) []string {
+	symlinks := []string{}
+	for _, f := range r.Files {
+		if f.Status == status {
+			symlinks = append(symlinks, f.Symlink)
+		}
+	}
+	return symlinks
+}
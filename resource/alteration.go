@@ -1,5 +1,7 @@
 package resource
 
+import "io"
+
 // Alteration is the interface defining structures that can take
 // a resource and return a new modified one.
 type Alteration interface {
@@ -22,15 +24,50 @@ func NewAlteredResource(r Resource, a ...Alteration) *AlteredResource {
 
 // Dump returns the content of an AlteredResource.
 func (ar *AlteredResource) Dump() ([]byte, error) {
+	r, err := ar.alter()
+	if err != nil {
+		return []byte{}, err
+	}
+	return r.Dump()
+}
+
+// Open returns a reader on the content of an AlteredResource.
+// The resource returned by the last alteration is opened with the Open function,
+// so its content is streamed if it implements the Opener interface.
+func (ar *AlteredResource) Open() (io.ReadCloser, error) {
+	r, err := ar.alter()
+	if err != nil {
+		return nil, err
+	}
+	return Open(r)
+}
+
+// Metadata returns the Metadata of AlteredResource.Resource
+// renamed by the alterations implementing the Renamer interface.
+// The size of the altered content is unknown.
+func (ar *AlteredResource) Metadata() Metadata {
+	m := MetadataOf(ar.Resource)
+	for _, alteration := range ar.Alterations {
+		if rn, ok := alteration.(Renamer); ok {
+			m = m.rename(rn)
+		}
+	}
+	m.Size = -1
+	return m
+}
+
+// alter applies the alterations with ApplyAlteration,
+// so the Metadata of the resource is kept.
+func (ar *AlteredResource) alter() (Resource, error) {
 	var err error
 	r := ar.Resource
 	for _, alteration := range ar.Alterations {
-		r, err = alteration.Alter(r)
+		r, err = ApplyAlteration(alteration, r)
 		if err != nil {
-			return []byte{}, err
+			return r, err
 		}
 	}
-	return r.Dump()
+	return r, nil
 }
 
 // In returns a new AlteredResource that is the same as the structed on which
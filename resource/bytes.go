@@ -1,8 +1,17 @@
 package resource
 
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
 // Bytes is a resource stored in a slice of bytes.
+// Meta is the optional Metadata of the content, for example
+// the Metadata of the resource it was created from (see ApplyAlteration).
 type Bytes struct {
 	Content []byte
+	Meta    Metadata
 }
 
 // NewString creates a Bytes resource from a string.
@@ -24,7 +33,22 @@ func (s *Bytes) Dump() ([]byte, error) {
 	return s.Content, nil
 }
 
+// Open returns a reader on the content of the resource.
+func (s *Bytes) Open() (io.ReadCloser, error) {
+	return ioutil.NopCloser(bytes.NewReader(s.Content)), nil
+}
+
+// Metadata returns Bytes.Meta with the size of the content.
+func (s *Bytes) Metadata() Metadata {
+	m := s.Meta
+	m.Size = int64(len(s.Content))
+	return m
+}
+
 // In returns a copy of the resource.
 func (s *Bytes) In(path string) Resource {
-	return NewBytes(s.Content)
+	return &Bytes{
+		Content: s.Content,
+		Meta:    s.Meta,
+	}
 }
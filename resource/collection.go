@@ -1,6 +1,9 @@
 package resource
 
-import "bytes"
+import (
+	"bytes"
+	"io"
+)
 
 // Collection is a list of resource.
 type Collection struct {
@@ -28,6 +31,45 @@ func (c *Collection) Dump() ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// Open returns a reader on the concatenation of the content of the resources
+// belonging to the collection. The resources are opened one after another,
+// so only one of them is open at a time.
+func (c *Collection) Open() (io.ReadCloser, error) {
+	return &multiReader{resources: c.Resources}, nil
+}
+
+// Metadata returns the Source (with its FS) and the Name of the first resource
+// of the Collection describing them. The ContentType is defined if all the resources
+// have the same one (otherwise MetadataOf guesses it from the Name or the Source).
+// The ModTime is the most recent one and the Size is the sum
+// of the sizes of the resources, if they are all known.
+func (c *Collection) Metadata() Metadata {
+	m := Metadata{}
+
+	for i, r := range c.Resources {
+		rm := MetadataOf(r)
+
+		if m.Source == "" && m.Name == "" {
+			m.Source, m.FS, m.Name = rm.Source, rm.FS, rm.Name
+		}
+		if i == 0 {
+			m.ContentType = rm.ContentType
+		} else if m.ContentType != rm.ContentType {
+			m.ContentType = ""
+		}
+		if rm.ModTime.After(m.ModTime) {
+			m.ModTime = rm.ModTime
+		}
+		if rm.Size < 0 || m.Size < 0 {
+			m.Size = -1
+		} else {
+			m.Size += rm.Size
+		}
+	}
+
+	return m
+}
+
 // In returns a copy of the Collection with the In method applied to
 // all resources in the collection.
 func (c *Collection) In(path string) Resource {
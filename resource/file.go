@@ -1,14 +1,18 @@
 package resource
 
 import (
+	"io"
 	"io/ioutil"
+	"os"
 
 	"github.com/sarulabs/statix/helpers"
 )
 
 // File is a resource which content is read from a file.
+// Name is the optional logical output path of the file (see Metadata).
 type File struct {
 	Path string
+	Name string
 }
 
 // NewFile creates a new File resource.
@@ -23,11 +27,28 @@ func (f *File) Dump() ([]byte, error) {
 	return ioutil.ReadFile(f.Path)
 }
 
+// Open returns a reader on the file.
+func (f *File) Open() (io.ReadCloser, error) {
+	return os.Open(f.Path)
+}
+
+// Metadata returns the path of the file as Source, and its
+// modification time and its size if the file can be read.
+func (f *File) Metadata() Metadata {
+	m := Metadata{Source: f.Path, Name: f.Name, Size: -1}
+	if info, err := os.Stat(f.Path); err == nil {
+		m.ModTime = info.ModTime()
+		m.Size = info.Size()
+	}
+	return m
+}
+
 // In creates a copy of File resource with its path modified.
 // If the File path is absolute, nothing changes.
 // If the File path is relative, it is rewritten to be based in the path parameter.
 func (f *File) In(path string) Resource {
 	return &File{
 		Path: helpers.RewritePath(path, f.Path),
+		Name: f.Name,
 	}
 }
@@ -0,0 +1,61 @@
+package resource
+
+import (
+	"io"
+	"io/fs"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+// FSFile is a resource which content is read from a file in an fs.FS
+// (for example an embed.FS or a zip archive).
+// Path uses slashes as separators. If it starts with a slash,
+// it is relative to the root of the fs.FS.
+// Name is the optional logical output path of the file (see Metadata).
+type FSFile struct {
+	FS   fs.FS
+	Path string
+	Name string
+}
+
+// NewFSFile creates a new FSFile resource.
+func NewFSFile(fsys fs.FS, path string) *FSFile {
+	return &FSFile{
+		FS:   fsys,
+		Path: path,
+	}
+}
+
+// Dump returns the content of the resource.
+func (f *FSFile) Dump() ([]byte, error) {
+	return fs.ReadFile(f.FS, helpers.FSPath(f.Path))
+}
+
+// Open returns a reader on the file.
+func (f *FSFile) Open() (io.ReadCloser, error) {
+	return f.FS.Open(helpers.FSPath(f.Path))
+}
+
+// In creates a copy of FSFile resource with its path modified.
+// If the FSFile path is absolute, nothing changes.
+// If the FSFile path is relative, it is rewritten to be based in the path parameter,
+// but only if the path parameter is a relative path that can be used in the fs.FS.
+// Absolute paths of the host file system are ignored.
+func (f *FSFile) In(path string) Resource {
+	return &FSFile{
+		FS:   f.FS,
+		Path: helpers.RewriteFSPath(path, f.Path),
+		Name: f.Name,
+	}
+}
+
+// Metadata returns the path of the file as Source, the FSFile.FS as FS,
+// and the modification time and the size of the file if it can be read.
+func (f *FSFile) Metadata() Metadata {
+	m := Metadata{Source: f.Path, FS: f.FS, Name: f.Name, Size: -1}
+	if info, err := fs.Stat(f.FS, helpers.FSPath(f.Path)); err == nil {
+		m.ModTime = info.ModTime()
+		m.Size = info.Size()
+	}
+	return m
+}
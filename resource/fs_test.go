@@ -0,0 +1,52 @@
+package resource
+
+import (
+	"bytes"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDumpFSFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"js/app.js": {Data: []byte("app")},
+	}
+
+	content, err := NewFSFile(fsys, "js/app.js").Dump()
+	if err != nil || !bytes.Equal(content, []byte("app")) {
+		t.Error("error dumping FSFile Resource")
+	}
+
+	content, err = NewFSFile(fsys, "/js/app.js").Dump()
+	if err != nil || !bytes.Equal(content, []byte("app")) {
+		t.Error("error dumping FSFile Resource with an absolute path")
+	}
+
+	content, err = readAll(NewFSFile(fsys, "js/app.js"))
+	if err != nil || !bytes.Equal(content, []byte("app")) {
+		t.Error("error opening FSFile Resource")
+	}
+
+	if _, err = NewFSFile(fsys, "js/missing.js").Dump(); err == nil {
+		t.Error("dumping a missing file should return an error")
+	}
+}
+
+func TestInFSFile(t *testing.T) {
+	f := NewFSFile(fstest.MapFS{}, "file/path")
+
+	if f.In("base").(*FSFile).Path != "base/file/path" {
+		t.Error("error while appling In to FSFile")
+	}
+
+	if f.In("/host/directory").(*FSFile).Path != "file/path" {
+		t.Error("absolute host paths should be ignored by In")
+	}
+
+	if NewFSFile(fstest.MapFS{}, "/file/path").In("base").(*FSFile).Path != "/file/path" {
+		t.Error("absolute paths should not be modified by In")
+	}
+
+	if f.Path != "file/path" {
+		t.Error("In should not alter original resources")
+	}
+}
@@ -0,0 +1,197 @@
+package resource
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+// Glob is a resource which content is the concatenation of the files
+// matching its Patterns. The syntax of the patterns is the one of filepath.Match.
+// The patterns are used in the given order, and the files matching each pattern
+// are sorted by name, so the content does not depend on the file system.
+// A file matching several patterns is only included once, at its first match.
+// Files matching one of the Excludes are ignored. An exclude can match
+// the whole filename or, if it does not contain a directory, only its base name.
+// If FS is defined, the files are read from this fs.FS instead of the
+// host file system, and the patterns use slashes as separators (see FSFile).
+type Glob struct {
+	Patterns []string
+	Excludes []string
+	FS       fs.FS
+}
+
+// NewGlob creates a new Glob resource.
+func NewGlob(patterns ...string) *Glob {
+	return &Glob{
+		Patterns: patterns,
+	}
+}
+
+// Files returns the list of files matching the Glob,
+// sorted by name for each pattern. Directories are ignored.
+func (g *Glob) Files() ([]string, error) {
+	files := []string{}
+	found := map[string]bool{}
+
+	for _, pattern := range g.Patterns {
+		matches, err := g.glob(pattern)
+		if err != nil {
+			return files, err
+		}
+		sort.Strings(matches)
+
+		for _, filename := range matches {
+			if found[filename] {
+				continue
+			}
+
+			excluded, err := g.excluded(filename)
+			if err != nil {
+				return files, err
+			}
+			if excluded {
+				continue
+			}
+
+			info, err := g.stat(filename)
+			if err != nil {
+				return files, err
+			}
+			if info.IsDir() {
+				continue
+			}
+
+			found[filename] = true
+			files = append(files, filename)
+		}
+	}
+
+	return files, nil
+}
+
+func (g *Glob) glob(pattern string) ([]string, error) {
+	if g.FS == nil {
+		return filepath.Glob(pattern)
+	}
+	return fs.Glob(g.FS, helpers.FSPath(pattern))
+}
+
+func (g *Glob) stat(filename string) (fs.FileInfo, error) {
+	if g.FS == nil {
+		return os.Stat(filename)
+	}
+	return fs.Stat(g.FS, filename)
+}
+
+func (g *Glob) excluded(filename string) (bool, error) {
+	for _, exclude := range g.Excludes {
+		if g.FS != nil {
+			exclude = helpers.FSPath(exclude)
+		}
+		for _, name := range []string{filename, filepath.Base(filename)} {
+			match, err := filepath.Match(exclude, name)
+			if err != nil || match {
+				return match, err
+			}
+		}
+	}
+	return false, nil
+}
+
+// Collection returns a Collection containing a File resource
+// for each file matching the Glob.
+func (g *Glob) Collection() (*Collection, error) {
+	files, err := g.Files()
+	if err != nil {
+		return NewCollection(), err
+	}
+
+	c := NewCollection()
+	for _, filename := range files {
+		if g.FS != nil {
+			c.Resources = append(c.Resources, NewFSFile(g.FS, filename))
+		} else {
+			c.Resources = append(c.Resources, NewFile(filename))
+		}
+	}
+
+	return c, nil
+}
+
+// Metadata returns the Metadata of the Collection of the files matching the Glob.
+func (g *Glob) Metadata() Metadata {
+	c, err := g.Collection()
+	if err != nil {
+		return Metadata{Size: -1}
+	}
+	return c.Metadata()
+}
+
+// Dump returns the concatenation of the content of the files matching the Glob.
+func (g *Glob) Dump() ([]byte, error) {
+	c, err := g.Collection()
+	if err != nil {
+		return []byte{}, err
+	}
+	return c.Dump()
+}
+
+// Open returns a reader on the concatenation of the content
+// of the files matching the Glob.
+func (g *Glob) Open() (io.ReadCloser, error) {
+	c, err := g.Collection()
+	if err != nil {
+		return nil, err
+	}
+	return c.Open()
+}
+
+// In creates a copy of the Glob resource with its patterns and excludes modified.
+// Absolute patterns do not change.
+// Relative patterns are rewritten to be based in the path parameter.
+// So are the relative excludes containing a directory. The excludes without
+// directory match base names, so they do not change.
+// If Glob.FS is defined, the paths are rewritten like the path of an FSFile.
+func (g *Glob) In(path string) Resource {
+	rewrite := helpers.RewritePath
+	if g.FS != nil {
+		rewrite = helpers.RewriteFSPath
+	}
+	return &Glob{
+		Patterns: rewritePaths(rewrite, path, g.Patterns),
+		Excludes: rewriteExcludes(rewrite, path, g.Excludes),
+		FS:       g.FS,
+	}
+}
+
+func rewritePaths(rewrite func(string, string) string, base string, paths []string) []string {
+	if paths == nil {
+		return nil
+	}
+	rewritten := []string{}
+	for _, p := range paths {
+		rewritten = append(rewritten, rewrite(base, p))
+	}
+	return rewritten
+}
+
+// rewriteExcludes rewrites the excludes containing a directory with rewritePaths.
+func rewriteExcludes(rewrite func(string, string) string, base string, excludes []string) []string {
+	if excludes == nil {
+		return nil
+	}
+	rewritten := []string{}
+	for _, exclude := range excludes {
+		if strings.ContainsAny(exclude, "/"+string(filepath.Separator)) {
+			exclude = rewrite(base, exclude)
+		}
+		rewritten = append(rewritten, exclude)
+	}
+	return rewritten
+}
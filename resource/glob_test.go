@@ -0,0 +1,112 @@
+package resource
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+func createGlobFiles() string {
+	dir, _ := helpers.TempDir("", "statix_glob_", "")
+	os.MkdirAll(filepath.Join(dir, "js/sub.js"), 0777)
+	ioutil.WriteFile(filepath.Join(dir, "js/b.js"), []byte("b"), 0777)
+	ioutil.WriteFile(filepath.Join(dir, "js/a.js"), []byte("a"), 0777)
+	ioutil.WriteFile(filepath.Join(dir, "js/c.min.js"), []byte("c"), 0777)
+	ioutil.WriteFile(filepath.Join(dir, "js/d.css"), []byte("d"), 0777)
+	return dir
+}
+
+func TestDumpGlob(t *testing.T) {
+	dir := createGlobFiles()
+	defer os.RemoveAll(dir)
+
+	g := NewGlob(filepath.Join(dir, "js/*.js"))
+	content, err := g.Dump()
+
+	if err != nil || !bytes.Equal(content, []byte("abc")) {
+		t.Error("error dumping Glob Resource: ", string(content))
+	}
+
+	g = NewGlob(filepath.Join(dir, "js/*.css"), filepath.Join(dir, "js/*"))
+	g.Excludes = []string{"*.min.js"}
+	content, err = g.Dump()
+
+	if err != nil || !bytes.Equal(content, []byte("dab")) {
+		t.Error("error dumping Glob Resource with excludes: ", string(content))
+	}
+
+	g = NewGlob(filepath.Join(dir, "js/b.js"), filepath.Join(dir, "js/*.js"))
+	content, err = g.Dump()
+
+	if err != nil || !bytes.Equal(content, []byte("bac")) {
+		t.Error("the files should be sorted for each pattern, in the order of the patterns: ", string(content))
+	}
+
+	g = NewGlob(filepath.Join(dir, "[.js"))
+	_, err = g.Dump()
+
+	if err == nil {
+		t.Error("invalid pattern should return an error")
+	}
+}
+
+func TestInGlob(t *testing.T) {
+	dir := createGlobFiles()
+	defer os.RemoveAll(dir)
+
+	g := NewGlob("js/*.js", "/abs/*.js")
+	g.Excludes = []string{"js/c.min.js", "*.min.js"}
+	gModified := g.In(dir).(*Glob)
+
+	if gModified.Patterns[0] != filepath.Join(dir, "js/*.js") || gModified.Patterns[1] != "/abs/*.js" {
+		t.Error("error while appling In to Glob")
+	}
+	if gModified.Excludes[0] != filepath.Join(dir, "js/c.min.js") || gModified.Excludes[1] != "*.min.js" {
+		t.Error("the excludes without directory should not be rewritten: ", gModified.Excludes)
+	}
+
+	if g.Patterns[0] != "js/*.js" {
+		t.Error("In should not alter original resources")
+	}
+
+	content, err := gModified.Dump()
+
+	if err != nil || !bytes.Equal(content, []byte("ab")) {
+		t.Error("error dumping Glob Resource after In: ", string(content))
+	}
+}
+
+func TestDumpGlobFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"assets/js/b.js":     {Data: []byte("b")},
+		"assets/js/a.js":     {Data: []byte("a")},
+		"assets/js/c.min.js": {Data: []byte("c")},
+		"assets/js/sub.js/x": {Data: []byte("x")},
+	}
+
+	g := NewGlob("js/*.js")
+	g.FS = fsys
+	g.Excludes = []string{"js/c.min.js"}
+	gModified := g.In("assets").(*Glob)
+
+	if gModified.Patterns[0] != "assets/js/*.js" || gModified.FS == nil {
+		t.Error("error while appling In to Glob with an FS")
+	}
+
+	content, err := gModified.Dump()
+
+	if err != nil || !bytes.Equal(content, []byte("ab")) {
+		t.Error("error dumping Glob Resource with an FS: ", string(content), err)
+	}
+
+	c, _ := gModified.Collection()
+
+	if _, ok := c.Resources[0].(*FSFile); !ok {
+		t.Error("Glob with an FS should contain FSFile resources")
+	}
+}
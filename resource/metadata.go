@@ -0,0 +1,119 @@
+package resource
+
+import (
+	"io/fs"
+	"mime"
+	"path"
+	"time"
+)
+
+// Metadata describes the origin of the content of a resource.
+// All the fields are optional.
+// Source is the path of the file the content comes from.
+// FS is the file system containing the Source if it is not a path
+// of the host file system (see FSFile). It is nil otherwise.
+// Name is the logical output path of the content, for example
+// the path of a file of an AssetPack inside its output directory.
+// It uses slashes as separators.
+// ContentType is the media type of the content.
+// ModTime and Size are hints: the last modification time of the source
+// and the size of the content in bytes (-1 if it is unknown).
+type Metadata struct {
+	Source      string
+	FS          fs.FS
+	Name        string
+	ContentType string
+	ModTime     time.Time
+	Size        int64
+}
+
+// Describer is implemented by the resources that know their Metadata.
+// It allows alterations to make decisions based on the origin of the content.
+type Describer interface {
+	Metadata() Metadata
+}
+
+// MetadataOf returns the Metadata of a resource.
+// If the resource does not implement the Describer interface,
+// the Metadata is empty and its Size is -1.
+// If the ContentType is not defined, it is guessed
+// from the extension of the Name, or of the Source.
+func MetadataOf(r Resource) Metadata {
+	m := Metadata{Size: -1}
+	if d, ok := r.(Describer); ok {
+		m = d.Metadata()
+	}
+
+	for _, name := range []string{m.Name, m.Source} {
+		if m.ContentType != "" || name == "" {
+			continue
+		}
+		m.ContentType = mime.TypeByExtension(path.Ext(name))
+	}
+
+	return m
+}
+
+// isZero checks if none of the fields of the Metadata is defined.
+// The Metadata can not be compared with == because of the FS.
+func (m Metadata) isZero() bool {
+	return m.Source == "" && m.FS == nil && m.Name == "" && m.ContentType == "" && m.ModTime.IsZero() && m.Size == 0
+}
+
+// rename applies the Renamer `rn` to the Name and the Source of the Metadata.
+// If the Name changes, the ContentType is cleared, so it can be guessed again.
+func (m Metadata) rename(rn Renamer) Metadata {
+	name := m.Name
+	if name == "" {
+		name = path.Base(m.Source)
+	}
+	if renamed := rn.Rename(name); renamed != name {
+		m.Name = renamed
+		m.ContentType = ""
+	}
+	return m
+}
+
+// ApplyAlteration applies the alteration `a` to `r`.
+// If the alteration returns a Bytes or a TempFile resource without Metadata,
+// it is given the Metadata of `r`, with its Size updated.
+// If the alteration implements the Renamer interface, it is applied to the Name.
+func ApplyAlteration(a Alteration, r Resource) (Resource, error) {
+	altered, err := a.Alter(r)
+	if err != nil {
+		return altered, err
+	}
+
+	var meta *Metadata
+
+	switch res := altered.(type) {
+	case *Bytes:
+		meta = &res.Meta
+	case *TempFile:
+		meta = &res.Meta
+	}
+
+	if meta == nil || !meta.isZero() {
+		return altered, nil
+	}
+
+	m := MetadataOf(r)
+	if m.Source == "" && m.Name == "" {
+		return altered, nil
+	}
+
+	if rn, ok := a.(Renamer); ok {
+		m = m.rename(rn)
+	}
+
+	if b, ok := altered.(*Bytes); ok {
+		m.Size = int64(len(b.Content))
+		return &Bytes{Content: b.Content, Meta: m}, nil
+	}
+
+	// the TempFile owns its file, so it can not be copied
+	m.Size = -1
+	*meta = m
+
+	return altered, nil
+}
@@ -0,0 +1,111 @@
+package resource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+// CompileAlteration is a ReverseAlteration renaming .src files into .css files.
+type CompileAlteration struct {
+	ReverseAlteration
+}
+
+func (ca CompileAlteration) Rename(name string) string {
+	return ReplaceExt(name, ".src", ".css")
+}
+
+func TestFileMetadata(t *testing.T) {
+	dir, _ := helpers.TempDir("", "statix_metadata_", "")
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "main.src")
+	os.WriteFile(filename, []byte("content"), 0777)
+
+	m := MetadataOf(&File{Path: filename, Name: "css/main.src"})
+	if m.Source != filename || m.Name != "css/main.src" || m.Size != 7 || m.ModTime.IsZero() {
+		t.Error("wrong metadata for a File: ", m)
+	}
+
+	m = MetadataOf(NewFile(filepath.Join(dir, "missing.js")))
+	if m.Size != -1 || m.ContentType != "text/javascript; charset=utf-8" {
+		t.Error("the size of a missing file should be unknown and its type guessed: ", m)
+	}
+
+	modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{"a.css": &fstest.MapFile{Data: []byte("abc"), ModTime: modTime}}
+
+	m = MetadataOf(NewFSFile(fsys, "a.css"))
+	if m.Source != "a.css" || m.Size != 3 || !m.ModTime.Equal(modTime) || m.ContentType != "text/css; charset=utf-8" {
+		t.Error("wrong metadata for an FSFile: ", m)
+	}
+}
+
+func TestCollectionMetadata(t *testing.T) {
+	modTime := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"a.css": &fstest.MapFile{Data: []byte("abc")},
+		"b.css": &fstest.MapFile{Data: []byte("de"), ModTime: modTime},
+		"c.js":  &fstest.MapFile{Data: []byte("f")},
+	}
+
+	c := NewCollection(NewString("x"), NewFSFile(fsys, "a.css"), NewFSFile(fsys, "b.css"))
+
+	m := c.Metadata()
+	if m.Source != "a.css" || m.Size != 6 || !m.ModTime.Equal(modTime) || m.ContentType != "" {
+		t.Error("wrong metadata for a Collection: ", m)
+	}
+
+	m = NewCollection(NewFSFile(fsys, "c.js"), NewFSFile(fsys, "a.css")).Metadata()
+	if m.ContentType != "" {
+		t.Error("the resources of the Collection do not have the same content type: ", m)
+	}
+
+	m = NewCollection(NewFSFile(fsys, "a.css"), NewFSFile(fsys, "b.css")).Metadata()
+	if m.ContentType != "text/css; charset=utf-8" {
+		t.Error("the resources of the Collection have the same content type: ", m)
+	}
+
+	g := NewGlob("*.js")
+	g.FS = fsys
+	if m = MetadataOf(g); m.Source != "c.js" || m.Size != 1 {
+		t.Error("wrong metadata for a Glob: ", m)
+	}
+
+	if m = MetadataOf(NewURL("http://example.com", "")); m.Size != -1 || m.Source != "" {
+		t.Error("a resource without metadata should have an unknown size: ", m)
+	}
+}
+
+func TestAlteredResourceMetadata(t *testing.T) {
+	fsys := fstest.MapFS{"main.src": &fstest.MapFile{Data: []byte("abc")}}
+
+	ar := NewAlteredResource(&FSFile{FS: fsys, Path: "main.src", Name: "css/main.src"}, CompileAlteration{}, ReverseAlteration{})
+
+	m := MetadataOf(ar)
+	if m.Name != "css/main.css" || m.ContentType != "text/css; charset=utf-8" || m.Size != -1 {
+		t.Error("the metadata of an AlteredResource should be renamed: ", m)
+	}
+
+	r, err := ar.alter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m = MetadataOf(r)
+	if m.Source != "main.src" || m.Name != "css/main.css" || m.Size != 3 || m.ContentType != "text/css; charset=utf-8" {
+		t.Error("the metadata should flow through the alterations: ", m)
+	}
+
+	b := &Bytes{Content: []byte("abc"), Meta: Metadata{ContentType: "text/plain"}}
+	if r, _ = ApplyAlteration(ReverseAlteration{}, b); MetadataOf(r).ContentType != "" {
+		t.Error("a resource without source and name should not give its metadata")
+	}
+	if m = MetadataOf(b.In("dir")); m.ContentType != "text/plain" || m.Size != 3 {
+		t.Error("the metadata of a Bytes resource should be kept by In: ", m)
+	}
+}
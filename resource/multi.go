@@ -0,0 +1,82 @@
+package resource
+
+import (
+	"io"
+	"path/filepath"
+)
+
+// Renamer is implemented by the alterations that change the type
+// of the content, like compilers. Rename returns the name the output
+// should have, given the name of the input. For example a stylus
+// compiler renames `main.styl` into `main.css`.
+type Renamer interface {
+	Rename(string) string
+}
+
+// ReplaceExt returns `name` with the extension `to` if its extension is `from`.
+// Otherwise `name` is returned as it is. It can be used to implement Renamer.
+func ReplaceExt(name, from, to string) string {
+	if filepath.Ext(name) != from {
+		return name
+	}
+	return name[:len(name)-len(from)] + to
+}
+
+// Extra is an additional output of a resource, for example a source map.
+// The name of the output is the name of the resource followed by Extra.Suffix.
+type Extra struct {
+	Suffix   string
+	Resource Resource
+}
+
+// MultiResource is implemented by the resources that come with extra outputs.
+// An alteration can return a MultiResource to produce several outputs from one input.
+type MultiResource interface {
+	Resource
+	Extras() []Extra
+}
+
+// Multi is a Resource with extra outputs. It implements the MultiResource interface.
+// Its content is the content of Multi.Resource.
+type Multi struct {
+	Resource     Resource
+	ExtraOutputs []Extra
+}
+
+// NewMulti creates a new Multi resource.
+func NewMulti(r Resource, extras ...Extra) *Multi {
+	return &Multi{
+		Resource:     r,
+		ExtraOutputs: extras,
+	}
+}
+
+// Dump returns the content of Multi.Resource.
+func (m *Multi) Dump() ([]byte, error) {
+	return m.Resource.Dump()
+}
+
+// Open returns a reader on the content of Multi.Resource.
+func (m *Multi) Open() (io.ReadCloser, error) {
+	return Open(m.Resource)
+}
+
+// In creates a copy of the Multi resource
+// with In applied to its resource and to its extra outputs.
+func (m *Multi) In(path string) Resource {
+	extras := []Extra{}
+	for _, e := range m.ExtraOutputs {
+		extras = append(extras, Extra{Suffix: e.Suffix, Resource: e.Resource.In(path)})
+	}
+	return NewMulti(m.Resource.In(path), extras...)
+}
+
+// Metadata returns the Metadata of Multi.Resource.
+func (m *Multi) Metadata() Metadata {
+	return MetadataOf(m.Resource)
+}
+
+// Extras returns the extra outputs of the resource.
+func (m *Multi) Extras() []Extra {
+	return m.ExtraOutputs
+}
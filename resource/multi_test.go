@@ -0,0 +1,32 @@
+package resource
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMulti(t *testing.T) {
+	m := NewMulti(NewFile("main.js"), Extra{Suffix: ".map", Resource: NewFile("main.js.map")})
+	mModified := m.In("/base").(*Multi)
+
+	if mModified.Resource.(*File).Path != "/base/main.js" {
+		t.Error("In should be applied to the resource")
+	}
+	if mModified.Extras()[0].Suffix != ".map" || mModified.Extras()[0].Resource.(*File).Path != "/base/main.js.map" {
+		t.Error("In should be applied to the extra outputs")
+	}
+
+	content, err := NewMulti(NewString("main")).Dump()
+	if err != nil || !bytes.Equal(content, []byte("main")) {
+		t.Error("Multi should dump its resource")
+	}
+}
+
+func TestReplaceExt(t *testing.T) {
+	if name := ReplaceExt("dir/main.styl", ".styl", ".css"); name != "dir/main.css" {
+		t.Error("wrong replaced extension: ", name)
+	}
+	if name := ReplaceExt("dir/main.js", ".styl", ".css"); name != "dir/main.js" {
+		t.Error("other extensions should not be replaced: ", name)
+	}
+}
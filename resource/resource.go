@@ -1,8 +1,15 @@
 package resource
 
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
 // Resource is the interface defining structures that can dump their content.
 // They should also have a In method to rewrite relative paths in the
 // resource definition.
+// Resources that can stream their content also implement the Opener interface.
 type Resource interface {
 	Dump() ([]byte, error)
 	In(string) Resource
@@ -16,6 +23,11 @@ func (e *Empty) Dump() ([]byte, error) {
 	return []byte{}, nil
 }
 
+// Open returns a reader without content.
+func (e *Empty) Open() (io.ReadCloser, error) {
+	return ioutil.NopCloser(strings.NewReader("")), nil
+}
+
 // In returns a copy of the Empty resource.
 func (e *Empty) In(path string) Resource {
 	return &Empty{}
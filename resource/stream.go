@@ -0,0 +1,80 @@
+package resource
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+)
+
+// Opener is implemented by the resources that can stream their content.
+// Reading a large resource with Open does not require to load
+// the whole content in memory as Dump does.
+type Opener interface {
+	Open() (io.ReadCloser, error)
+}
+
+// Open returns a reader on the content of a resource.
+// If the resource implements the Opener interface, its Open method is used.
+// Otherwise the resource is dumped and its content is read from memory.
+// The caller should close the returned reader.
+func Open(r Resource) (io.ReadCloser, error) {
+	if o, ok := r.(Opener); ok {
+		return o.Open()
+	}
+
+	c, err := r.Dump()
+	if err != nil {
+		return nil, err
+	}
+
+	return ioutil.NopCloser(bytes.NewReader(c)), nil
+}
+
+// multiReader reads the content of a list of resources one after another.
+// Only one resource is open at a time.
+type multiReader struct {
+	resources []Resource
+	current   io.ReadCloser
+}
+
+func (mr *multiReader) Read(p []byte) (int, error) {
+	for {
+		if mr.current == nil {
+			if len(mr.resources) == 0 {
+				return 0, io.EOF
+			}
+
+			rc, err := Open(mr.resources[0])
+			if err != nil {
+				return 0, err
+			}
+
+			mr.current = rc
+			mr.resources = mr.resources[1:]
+		}
+
+		n, err := mr.current.Read(p)
+		if err == io.EOF {
+			err = mr.current.Close()
+			mr.current = nil
+			if err != nil {
+				return n, err
+			}
+			if n == 0 {
+				continue
+			}
+		}
+
+		return n, err
+	}
+}
+
+func (mr *multiReader) Close() error {
+	if mr.current == nil {
+		return nil
+	}
+	err := mr.current.Close()
+	mr.current = nil
+	mr.resources = nil
+	return err
+}
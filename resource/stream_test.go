@@ -0,0 +1,108 @@
+package resource
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// dumpOnly is a resource that does not implement the Opener interface.
+type dumpOnly struct {
+	content []byte
+	err     error
+}
+
+func (d *dumpOnly) Dump() ([]byte, error)   { return d.content, d.err }
+func (d *dumpOnly) In(path string) Resource { return d }
+
+func readAll(r Resource) ([]byte, error) {
+	rc, err := Open(r)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
+
+func TestOpen(t *testing.T) {
+	content, err := readAll(&dumpOnly{content: []byte("dump")})
+	if err != nil || !bytes.Equal(content, []byte("dump")) {
+		t.Error("Open should use Dump for resources that are not Openers")
+	}
+
+	_, err = readAll(&dumpOnly{err: errors.New("error")})
+	if err == nil {
+		t.Error("Open should return the Dump error")
+	}
+
+	content, err = readAll(&Empty{})
+	if err != nil || len(content) != 0 {
+		t.Error("error opening Empty Resource")
+	}
+}
+
+func TestOpenFile(t *testing.T) {
+	tmp, _ := ioutil.TempFile("", "statix_stream_")
+	tmp.Write([]byte("file"))
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	content, err := readAll(NewFile(tmp.Name()))
+	if err != nil || !bytes.Equal(content, []byte("file")) {
+		t.Error("error opening File Resource")
+	}
+
+	_, err = readAll(NewFile(tmp.Name() + "_missing"))
+	if err == nil {
+		t.Error("opening a missing file should return an error")
+	}
+}
+
+func TestOpenCollection(t *testing.T) {
+	c := NewCollection(
+		NewString("string1"),
+		NewCollection(&Empty{}, NewString("string2")),
+		NewAlteredResource(NewString("3gnirts"), ReverseAlteration{}),
+		&dumpOnly{content: []byte("string4")},
+	)
+
+	content, err := readAll(c)
+	if err != nil || !bytes.Equal(content, []byte("string1string2string3string4")) {
+		t.Error("error opening Collection Resource: ", string(content))
+	}
+
+	c = NewCollection(NewString("string1"), &dumpOnly{err: errors.New("error")})
+
+	_, err = readAll(c)
+	if err == nil {
+		t.Error("error of a resource should be returned when reading a Collection")
+	}
+}
+
+func TestOpenCollectionSmallBuffer(t *testing.T) {
+	c := NewCollection(NewString("abc"), NewString("def"))
+
+	rc, _ := c.Open()
+	defer rc.Close()
+
+	buf := bytes.NewBuffer(nil)
+	p := make([]byte, 2)
+	for {
+		n, err := rc.Read(p)
+		buf.Write(p[:n])
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	if buf.String() != "abcdef" {
+		t.Error("error reading Collection Resource by chunks: ", buf.String())
+	}
+}
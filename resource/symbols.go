@@ -0,0 +1,333 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SymbolSprite is a resource merging SVG files into a single <svg> element
+// containing a <symbol> for each file. It can be used for inline icons,
+// with <use href="#home"/>.
+// Input contains the SVG files. It can be a Glob, a File, an FSFile
+// or a Collection of these resources.
+// The id of each symbol is SymbolSprite.Prefix followed by the name
+// of the file without its extension.
+// The ids used in several files, or equal to the id of a symbol, are
+// prefixed by the id of the symbol, and so are the references to them.
+// The presentation attributes of the root of a file (fill, stroke, ...)
+// are kept on a <g> element wrapping the content of its symbol,
+// and its namespace declarations are moved to the root of the sprite.
+type SymbolSprite struct {
+	Input  Resource
+	Prefix string
+}
+
+// NewSymbolSprite creates a new SymbolSprite resource.
+func NewSymbolSprite(input Resource) *SymbolSprite {
+	return &SymbolSprite{
+		Input: input,
+	}
+}
+
+// Dump returns the SVG image containing the symbols.
+func (s *SymbolSprite) Dump() ([]byte, error) {
+	files, err := namedResources(s.Input)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	symbols := []*svgSymbol{}
+	symbolIDs := map[string]string{}
+	idCount := map[string]int{}
+	namespaces := map[string]string{"xlink": xlinkNamespace}
+
+	for _, f := range files {
+		id := s.Prefix + symbolNameRegexp.ReplaceAllString(strings.TrimSuffix(f.name, path.Ext(f.name)), "-")
+		if previous, ok := symbolIDs[id]; ok {
+			return []byte{}, fmt.Errorf("`%s` and `%s` give the same symbol id `%s`", previous, f.name, id)
+		}
+		symbolIDs[id] = f.name
+
+		c, err := f.resource.Dump()
+		if err != nil {
+			return []byte{}, err
+		}
+
+		symbol, err := newSVGSymbol(id, c)
+		if err != nil {
+			return []byte{}, fmt.Errorf("%s: %s", f.name, err)
+		}
+
+		for innerID := range symbol.ids {
+			idCount[innerID]++
+		}
+
+		for _, ns := range symbol.namespaces {
+			if url, ok := namespaces[ns.Name.Local]; ok && url != ns.Value {
+				return []byte{}, fmt.Errorf("%s: the namespace prefix `%s` is already bound to `%s`", f.name, ns.Name.Local, url)
+			}
+			namespaces[ns.Name.Local] = ns.Value
+		}
+
+		symbols = append(symbols, symbol)
+	}
+
+	prefixes := []string{}
+	for prefix := range namespaces {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	buf := bytes.NewBufferString(`<svg xmlns="http://www.w3.org/2000/svg"`)
+	for _, prefix := range prefixes {
+		buf.WriteString(" xmlns:" + prefix + `="`)
+		xml.EscapeText(buf, []byte(namespaces[prefix]))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString(">\n")
+
+	for _, symbol := range symbols {
+		conflicts := map[string]bool{}
+		for innerID := range symbol.ids {
+			if _, ok := symbolIDs[innerID]; ok || idCount[innerID] > 1 {
+				conflicts[innerID] = true
+			}
+		}
+		symbol.write(buf, conflicts)
+	}
+
+	buf.WriteString("</svg>\n")
+
+	return buf.Bytes(), nil
+}
+
+// In returns a copy of the SymbolSprite with the In method applied to its Input.
+func (s *SymbolSprite) In(path string) Resource {
+	return &SymbolSprite{
+		Input:  s.Input.In(path),
+		Prefix: s.Prefix,
+	}
+}
+
+// namedResource is a resource with the base name of its file.
+type namedResource struct {
+	name     string
+	resource Resource
+}
+
+// namedResources returns the files contained in `r`.
+func namedResources(r Resource) ([]namedResource, error) {
+	switch r := r.(type) {
+	case *File:
+		return []namedResource{{name: filepath.Base(r.Path), resource: r}}, nil
+	case *FSFile:
+		return []namedResource{{name: path.Base(r.Path), resource: r}}, nil
+	case *Glob:
+		c, err := r.Collection()
+		if err != nil {
+			return nil, err
+		}
+		return namedResources(c)
+	case *Collection:
+		files := []namedResource{}
+		for _, child := range r.Resources {
+			childFiles, err := namedResources(child)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, childFiles...)
+		}
+		return files, nil
+	}
+	return nil, fmt.Errorf("the file name of a %T resource is unknown", r)
+}
+
+var (
+	// symbolNameRegexp matches the characters replaced in the ids of the symbols.
+	symbolNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+	// svgIDRegexp matches the id attributes.
+	svgIDRegexp = regexp.MustCompile(`(\sid\s*=\s*["'])([^"']+)(["'])`)
+	// svgRefRegexp matches the references to an id in the href attributes and in url().
+	svgRefRegexp = regexp.MustCompile(`((?:href\s*=\s*["'])|(?:url\(\s*["']?))#([^"')\s]+)`)
+	// svgRootAttrs are the attributes of the root element that
+	// only apply to the <svg> element and are not kept on the <g> element.
+	svgRootAttrs = map[string]bool{
+		"xmlns": true, "id": true, "x": true, "y": true, "width": true, "height": true,
+		"viewBox": true, "preserveAspectRatio": true, "version": true, "baseProfile": true,
+		"zoomAndPan": true, "contentScriptType": true, "contentStyleType": true,
+	}
+)
+
+// xlinkNamespace is the namespace of the xlink prefix.
+const xlinkNamespace = "http://www.w3.org/1999/xlink"
+
+// svgSymbol is an SVG file converted into a <symbol>.
+// The groupAttrs are the presentation attributes of the root element,
+// written on a <g> element wrapping the content.
+// The namespaces are the namespace declarations of the root element.
+type svgSymbol struct {
+	id         string
+	attrs      []xml.Attr
+	groupAttrs []xml.Attr
+	namespaces []xml.Attr
+	content    []byte
+	ids        map[string]bool
+}
+
+// newSVGSymbol reads the root element of the SVG file `c`.
+// The attributes of the symbol are the viewBox and the preserveAspectRatio of the root.
+// If there is no viewBox, it is built from the width and the height.
+// The other attributes of the root, except the ones in svgRootAttrs,
+// are kept as groupAttrs, and the prefixed namespace declarations as namespaces.
+func newSVGSymbol(id string, c []byte) (*svgSymbol, error) {
+	e, err := ParseSVGElement(c)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &svgSymbol{id: id, content: e.Content, ids: SVGIDs(e.Content)}
+
+	attrs := map[string]string{}
+	for _, a := range e.Attrs {
+		switch {
+		case a.Name.Space == "xmlns":
+			s.namespaces = append(s.namespaces, a)
+		case a.Name.Space != "":
+			// the other prefixed attributes (editor metadata) are dropped
+		case !svgRootAttrs[a.Name.Local]:
+			s.groupAttrs = append(s.groupAttrs, a)
+		default:
+			attrs[a.Name.Local] = a.Value
+		}
+	}
+
+	viewBox := attrs["viewBox"]
+	if viewBox == "" && attrs["width"] != "" && attrs["height"] != "" {
+		viewBox = "0 0 " + strings.TrimSuffix(attrs["width"], "px") + " " + strings.TrimSuffix(attrs["height"], "px")
+	}
+	if viewBox != "" {
+		s.attrs = append(s.attrs, xml.Attr{Name: xml.Name{Local: "viewBox"}, Value: viewBox})
+	}
+	if par := attrs["preserveAspectRatio"]; par != "" {
+		s.attrs = append(s.attrs, xml.Attr{Name: xml.Name{Local: "preserveAspectRatio"}, Value: par})
+	}
+
+	return s, nil
+}
+
+// write writes the <symbol> in `buf`. The `conflicts` ids and
+// the references to them are prefixed by the id of the symbol.
+func (s *svgSymbol) write(buf *bytes.Buffer, conflicts map[string]bool) {
+	buf.WriteString(`<symbol id="`)
+	xml.EscapeText(buf, []byte(s.id))
+	buf.WriteString(`"`)
+
+	writeSVGAttrs(buf, s.attrs)
+	buf.WriteString(">")
+
+	if len(s.groupAttrs) > 0 {
+		buf.WriteString("<g")
+		writeSVGAttrs(buf, s.groupAttrs)
+		buf.WriteString(">")
+	}
+
+	buf.Write(PrefixSVGIDs(s.content, s.id, conflicts))
+
+	if len(s.groupAttrs) > 0 {
+		buf.WriteString("</g>")
+	}
+	buf.WriteString("</symbol>\n")
+}
+
+// writeSVGAttrs writes the attributes `attrs` in `buf`.
+func writeSVGAttrs(buf *bytes.Buffer, attrs []xml.Attr) {
+	for _, a := range attrs {
+		buf.WriteString(" " + a.Name.Local + `="`)
+		xml.EscapeText(buf, []byte(a.Value))
+		buf.WriteString(`"`)
+	}
+}
+
+// SVGElement is the root <svg> element of an SVG file.
+// Attrs are the attributes of the element, with their prefix in Name.Space,
+// and Content is the markup between its start tag and its end tag.
+type SVGElement struct {
+	Attrs   []xml.Attr
+	Content []byte
+}
+
+// ParseSVGElement reads the root element of the SVG file `c` with encoding/xml.
+// The xml declaration, the doctype and the comments before it are skipped.
+// It returns an error if the root element is not an <svg> element.
+func ParseSVGElement(c []byte) (*SVGElement, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(c))
+
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return nil, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "svg" {
+			return nil, errors.New("the root element is not an svg element")
+		}
+
+		e := &SVGElement{Attrs: start.Attr}
+
+		offset := decoder.InputOffset()
+		if !bytes.HasSuffix(c[:offset], []byte("/>")) {
+			end := bytes.LastIndex(c, []byte("</svg>"))
+			if end < int(offset) {
+				return nil, errors.New("the svg element is not closed")
+			}
+			e.Content = bytes.TrimSpace(c[offset:end])
+		}
+
+		return e, nil
+	}
+}
+
+// SVGIDs returns the values of the id attributes of the SVG markup `c`.
+func SVGIDs(c []byte) map[string]bool {
+	ids := map[string]bool{}
+	for _, m := range svgIDRegexp.FindAllSubmatch(c, -1) {
+		ids[string(m[2])] = true
+	}
+	return ids
+}
+
+// PrefixSVGIDs returns the SVG markup `c` with the `conflicts` ids,
+// and the references to them in the href attributes and in url(),
+// prefixed by `prefix` followed by a dash.
+func PrefixSVGIDs(c []byte, prefix string, conflicts map[string]bool) []byte {
+	if len(conflicts) == 0 {
+		return c
+	}
+
+	rewrite := func(id string) string {
+		if conflicts[id] {
+			return prefix + "-" + id
+		}
+		return id
+	}
+
+	c = svgIDRegexp.ReplaceAllFunc(c, func(m []byte) []byte {
+		sub := svgIDRegexp.FindSubmatch(m)
+		return []byte(string(sub[1]) + rewrite(string(sub[2])) + string(sub[3]))
+	})
+	return svgRefRegexp.ReplaceAllFunc(c, func(m []byte) []byte {
+		sub := svgRefRegexp.FindSubmatch(m)
+		return []byte(string(sub[1]) + "#" + rewrite(string(sub[2])))
+	})
+}
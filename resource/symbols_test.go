@@ -0,0 +1,115 @@
+package resource
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+const (
+	testArrowSVG = `<?xml version="1.0"?>
+<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16" preserveAspectRatio="none">
+  <defs><linearGradient id="g"/></defs>
+  <path id="arrow" fill="url(#g)" d="M0 0h16v16z"/>
+</svg>`
+	testHomeSVG = `<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="24px" height="20">
+  <circle id="g" r="2"/><rect id="only" width="1" height="1"/><use xlink:href="#g"/>
+</svg>`
+)
+
+func TestDumpSymbolSprite(t *testing.T) {
+	dir, _ := helpers.TempDir("", "statix_symbols_", "")
+	defer os.RemoveAll(dir)
+
+	os.WriteFile(filepath.Join(dir, "arrow.svg"), []byte(testArrowSVG), 0777)
+	os.WriteFile(filepath.Join(dir, "home.svg"), []byte(testHomeSVG), 0777)
+
+	s := NewSymbolSprite(NewGlob("*.svg")).In(dir).(*SymbolSprite)
+	s.Prefix = "i-"
+
+	content, err := s.Dump()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svg := string(content)
+
+	expected := []string{
+		`<symbol id="i-arrow" viewBox="0 0 16 16" preserveAspectRatio="none"><defs><linearGradient id="i-arrow-g"/></defs>`,
+		`<path id="arrow" fill="url(#i-arrow-g)"`,
+		`<symbol id="i-home" viewBox="0 0 24 20"><circle id="i-home-g" r="2"/><rect id="only"`,
+		`<use xlink:href="#i-home-g"/>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Error("the sprite should contain ", e, ": ", svg)
+		}
+	}
+	if strings.Contains(svg, "<?xml") || strings.Count(svg, "<svg") != 1 {
+		t.Error("the root elements of the files should be removed: ", svg)
+	}
+}
+
+func TestDumpSymbolSpriteConflicts(t *testing.T) {
+	fsys := fstest.MapFS{
+		"arrow.svg": &fstest.MapFile{Data: []byte(`<svg viewBox="0 0 1 1"><g id="home"/></svg>`)},
+		"home.svg":  &fstest.MapFile{Data: []byte(`<svg viewBox="0 0 1 1"/>`)},
+		"home.png":  &fstest.MapFile{Data: []byte(`png`)},
+	}
+
+	s := NewSymbolSprite(NewCollection(NewFSFile(fsys, "arrow.svg"), NewFSFile(fsys, "home.svg")))
+
+	content, err := s.Dump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), `<g id="arrow-home"/>`) || !strings.Contains(string(content), `<symbol id="home" viewBox="0 0 1 1"></symbol>`) {
+		t.Error("an id equal to the id of a symbol should be rewritten: ", string(content))
+	}
+
+	s = NewSymbolSprite(NewCollection(NewFSFile(fsys, "home.svg"), NewFSFile(fsys, "home.png")))
+	if _, err = s.Dump(); err == nil {
+		t.Error("two files with the same name should return an error")
+	}
+
+	s = NewSymbolSprite(NewString(`<svg/>`))
+	if _, err = s.Dump(); err == nil {
+		t.Error("a resource without file name should return an error")
+	}
+}
+
+func TestDumpSymbolSpriteRootAttributes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"check.svg": &fstest.MapFile{Data: []byte(`<svg xmlns="http://www.w3.org/2000/svg" xmlns:sketch="http://example.com/sketch" ` +
+			`width="24" height="24" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round">` +
+			`<path sketch:type="check" d="M5 12l5 5L20 7"/></svg>`)},
+		"other.svg": &fstest.MapFile{Data: []byte(`<svg xmlns:sketch="http://example.com/other" viewBox="0 0 1 1"/>`)},
+	}
+
+	content, err := NewSymbolSprite(NewFSFile(fsys, "check.svg")).Dump()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	svg := string(content)
+
+	expected := []string{
+		`<svg xmlns="http://www.w3.org/2000/svg" xmlns:sketch="http://example.com/sketch" xmlns:xlink="http://www.w3.org/1999/xlink">`,
+		`<symbol id="check" viewBox="0 0 24 24"><g fill="none" stroke="currentColor" stroke-width="2" stroke-linecap="round">` +
+			`<path sketch:type="check" d="M5 12l5 5L20 7"/></g></symbol>`,
+	}
+	for _, e := range expected {
+		if !strings.Contains(svg, e) {
+			t.Error("the sprite should contain ", e, ": ", svg)
+		}
+	}
+
+	s := NewSymbolSprite(NewCollection(NewFSFile(fsys, "check.svg"), NewFSFile(fsys, "other.svg")))
+	if _, err = s.Dump(); err == nil {
+		t.Error("a prefix bound to two namespaces should return an error")
+	}
+}
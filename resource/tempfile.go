@@ -0,0 +1,86 @@
+package resource
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+)
+
+// TempFile is a resource which content is read from a temporary file,
+// for example the output of a command (see alteration.ExecCommand).
+// The content is not loaded in memory, it can be streamed with Open.
+// The file is removed when the TempFile is garbage collected,
+// or earlier with Remove.
+// Meta is the optional Metadata of the content (see ApplyAlteration).
+type TempFile struct {
+	Path string
+	Meta Metadata
+}
+
+// NewTempFile creates a TempFile resource that owns the file `path`.
+func NewTempFile(path string) *TempFile {
+	f := &TempFile{
+		Path: path,
+	}
+	runtime.SetFinalizer(f, (*TempFile).Remove)
+	return f
+}
+
+// Dump returns the content of the resource.
+func (f *TempFile) Dump() ([]byte, error) {
+	c, err := ioutil.ReadFile(f.Path)
+	runtime.KeepAlive(f)
+	return c, err
+}
+
+// Open returns a reader on the file.
+// The file is not removed before the reader is closed.
+func (f *TempFile) Open() (io.ReadCloser, error) {
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &tempFileReader{File: file, owner: f}, nil
+}
+
+// Metadata returns TempFile.Meta with the size of the file.
+func (f *TempFile) Metadata() Metadata {
+	m := f.Meta
+	m.Size = -1
+	if info, err := os.Stat(f.Path); err == nil {
+		m.Size = info.Size()
+	}
+	runtime.KeepAlive(f)
+	return m
+}
+
+// In returns the resource itself.
+// The path of a TempFile is not relative to a directory,
+// and a copy would remove the file a second time.
+func (f *TempFile) In(path string) Resource {
+	return f
+}
+
+// Remove deletes the file. The resource can not be used anymore.
+func (f *TempFile) Remove() error {
+	runtime.SetFinalizer(f, nil)
+	err := os.Remove(f.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// tempFileReader keeps a reference to the TempFile,
+// so the file is not removed while it is read.
+type tempFileReader struct {
+	*os.File
+	owner *TempFile
+}
+
+func (r *tempFileReader) Close() error {
+	err := r.File.Close()
+	r.owner = nil
+	return err
+}
@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestTempFile(t *testing.T) {
+	tmp, err := ioutil.TempFile("", "statix_filter_")
+	if err != nil {
+		t.Fatal("could not create temporary file", err)
+	}
+	tmp.WriteString("temp")
+	tmp.Close()
+
+	f := NewTempFile(tmp.Name())
+
+	if c, err := f.Dump(); err != nil || !bytes.Equal(c, []byte("temp")) {
+		t.Error("could not dump the TempFile", string(c), err)
+	}
+	if c, err := readAll(f); err != nil || !bytes.Equal(c, []byte("temp")) {
+		t.Error("could not read the TempFile", string(c), err)
+	}
+	if m := f.Metadata(); m.Size != 4 {
+		t.Error("the Size should be the size of the file", m.Size)
+	}
+	if f.In("dir") != f {
+		t.Error("In should not copy the TempFile")
+	}
+
+	if err := f.Remove(); err != nil {
+		t.Error("could not remove the TempFile", err)
+	}
+	if _, err := os.Stat(tmp.Name()); !os.IsNotExist(err) {
+		t.Error("the file should be removed", err)
+	}
+	if err := f.Remove(); err != nil {
+		t.Error("removing a TempFile twice should not fail", err)
+	}
+}
+
+// tempFileAlteration is an alteration returning a TempFile.
+type tempFileAlteration struct {
+	out *TempFile
+}
+
+func (a tempFileAlteration) Alter(r Resource) (Resource, error) {
+	return a.out, nil
+}
+
+func TestApplyAlterationTempFile(t *testing.T) {
+	tmp, err := ioutil.TempFile("", "statix_filter_")
+	if err != nil {
+		t.Fatal("could not create temporary file", err)
+	}
+	tmp.Close()
+
+	out := NewTempFile(tmp.Name())
+	defer out.Remove()
+
+	altered, err := ApplyAlteration(tempFileAlteration{out}, &File{Path: "css/main.css", Name: "main.css"})
+	if err != nil || altered != out {
+		t.Fatal("the TempFile should be returned", altered, err)
+	}
+	if m := out.Metadata(); m.Source != "css/main.css" || m.Name != "main.css" || m.Size != 0 {
+		t.Error("the TempFile should get the Metadata of the input", m)
+	}
+}
@@ -0,0 +1,71 @@
+package resource
+
+import (
+	"bytes"
+	"text/template"
+)
+
+// Template is a resource which content is the result of a text/template.
+// The template is read from the Input resource (for example a File or a Bytes resource)
+// and executed with Data. Funcs are added to the template before it is parsed.
+// If Scope is defined, the functions it creates for each execution
+// are also added (see FuncsScope).
+type Template struct {
+	Input Resource
+	Data  interface{}
+	Funcs template.FuncMap
+	Scope FuncsScope
+}
+
+// FuncsScope creates the functions of one execution of a Template.
+// The functions can share a state during the execution (for example a cache)
+// without sharing it with the other executions.
+type FuncsScope interface {
+	Funcs() template.FuncMap
+}
+
+// NewTemplate creates a new Template resource.
+func NewTemplate(input Resource, data interface{}, funcs template.FuncMap) *Template {
+	return &Template{
+		Input: input,
+		Data:  data,
+		Funcs: funcs,
+	}
+}
+
+// Dump returns the result of the template execution.
+func (t *Template) Dump() ([]byte, error) {
+	c, err := t.Input.Dump()
+	if err != nil {
+		return []byte{}, err
+	}
+
+	tmpl := template.New("statix").Funcs(t.Funcs)
+	if t.Scope != nil {
+		tmpl = tmpl.Funcs(t.Scope.Funcs())
+	}
+
+	tmpl, err = tmpl.Parse(string(c))
+	if err != nil {
+		return []byte{}, err
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	err = tmpl.Execute(buf, t.Data)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// In returns a copy of the Template with the In method applied to its Input.
+func (t *Template) In(path string) Resource {
+	return &Template{
+		Input: t.Input.In(path),
+		Data:  t.Data,
+		Funcs: t.Funcs,
+		Scope: t.Scope,
+	}
+}
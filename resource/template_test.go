@@ -0,0 +1,70 @@
+package resource
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestDumpTemplate(t *testing.T) {
+	tmpl := NewTemplate(
+		NewString(`var env = "{{.Env}}"; var url = "{{upper "url"}}";`),
+		map[string]string{"Env": "prod"},
+		template.FuncMap{"upper": strings.ToUpper},
+	)
+	content, err := tmpl.Dump()
+
+	if err != nil || !bytes.Equal(content, []byte(`var env = "prod"; var url = "URL";`)) {
+		t.Error("error dumping Template Resource: ", string(content), err)
+	}
+
+	tmpl = NewTemplate(NewString(`{{.Env`), nil, nil)
+
+	if _, err = tmpl.Dump(); err == nil {
+		t.Error("invalid template should return an error")
+	}
+
+	c := NewCollection(NewString("a"), NewTemplate(NewString("{{.}}"), "b", nil))
+	content, _ = c.Dump()
+
+	if !bytes.Equal(content, []byte("ab")) {
+		t.Error("error dumping Template Resource in a Collection: ", string(content))
+	}
+}
+
+func TestInTemplate(t *testing.T) {
+	tmpl := NewTemplate(NewFile("file/path"), "data", nil)
+	tmplModified := tmpl.In("base").(*Template)
+
+	if tmplModified.Input.(*File).Path != "base/file/path" || tmplModified.Data != "data" {
+		t.Error("error while appling In to Template")
+	}
+
+	if tmpl.Input.(*File).Path != "file/path" {
+		t.Error("In should not alter original resources")
+	}
+}
+
+// counterScope creates a `count` function counting its calls during an execution.
+type counterScope struct{}
+
+func (cs counterScope) Funcs() template.FuncMap {
+	n := 0
+	return template.FuncMap{"count": func() int {
+		n++
+		return n
+	}}
+}
+
+func TestTemplateScope(t *testing.T) {
+	tmpl := NewTemplate(NewString(`{{count}}{{count}}`), nil, nil)
+	tmpl.Scope = counterScope{}
+
+	for i := 0; i < 2; i++ {
+		content, err := tmpl.In("base").Dump()
+		if err != nil || string(content) != "12" {
+			t.Error("each execution should have its own functions: ", string(content), err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package resource
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+// URL is a resource which content is downloaded over http.
+//
+// Hash is the expected hash of the content. It is required, so a remote file can not
+// change without being noticed. It can be written `sha256:{hex}` or `sha384:{hex}`,
+// or with the subresource integrity syntax `sha256-{base64}` or `sha384-{base64}`.
+//
+// If CacheDir is defined, downloaded files are stored in this directory
+// and are not downloaded again. If Offline is true, the file is never downloaded
+// and Dump fails if the file is not in the cache.
+//
+// Client is the http.Client used to download the file.
+// If it is nil, http.DefaultClient is used.
+type URL struct {
+	URL      string
+	Hash     string
+	CacheDir string
+	Offline  bool
+	Client   *http.Client
+}
+
+// NewURL creates a new URL resource.
+func NewURL(url, hash string) *URL {
+	return &URL{
+		URL:  url,
+		Hash: hash,
+	}
+}
+
+// Dump returns the content of the resource.
+// The content is read from the cache or downloaded.
+// An error is returned if the content does not match URL.Hash.
+func (u *URL) Dump() ([]byte, error) {
+	algo, expected, err := u.parseHash()
+	if err != nil {
+		return []byte{}, err
+	}
+
+	cacheFile := ""
+	if u.CacheDir != "" {
+		cacheFile = filepath.Join(u.CacheDir, algo+"-"+hex.EncodeToString(expected))
+
+		c, err := ioutil.ReadFile(cacheFile)
+		if err == nil && u.checkHash(algo, expected, c) == nil {
+			return c, nil
+		}
+	}
+
+	if u.Offline {
+		return []byte{}, fmt.Errorf("`%s` is not in the cache and can not be downloaded in offline mode", u.URL)
+	}
+
+	c, err := u.download()
+	if err != nil {
+		return []byte{}, err
+	}
+
+	err = u.checkHash(algo, expected, c)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	if cacheFile != "" {
+		err = os.MkdirAll(u.CacheDir, 0755)
+		if err == nil {
+			err = helpers.WriteFile(cacheFile, c, 0644)
+		}
+		if err != nil {
+			return []byte{}, err
+		}
+	}
+
+	return c, nil
+}
+
+func (u *URL) download() ([]byte, error) {
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	resp, err := client.Get(u.URL)
+	if err != nil {
+		return []byte{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return []byte{}, fmt.Errorf("could not download `%s`: %s", u.URL, resp.Status)
+	}
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// parseHash returns the algorithm and the expected digest defined in URL.Hash.
+func (u *URL) parseHash() (string, []byte, error) {
+	var algo, value string
+	var digest []byte
+	var err error
+
+	if u.Hash == "" {
+		return "", nil, fmt.Errorf("a hash is required to download `%s`", u.URL)
+	}
+
+	if i := strings.IndexAny(u.Hash, ":-"); i >= 0 {
+		algo, value = u.Hash[:i], u.Hash[i+1:]
+		if u.Hash[i] == ':' {
+			digest, err = hex.DecodeString(value)
+		} else {
+			digest, err = base64.StdEncoding.DecodeString(value)
+		}
+	}
+
+	h := newHash(algo)
+
+	if h == nil || err != nil || len(digest) != h.Size() {
+		return "", nil, fmt.Errorf("hash `%s` is not valid, it should be a sha256 or a sha384 hash", u.Hash)
+	}
+
+	return algo, digest, nil
+}
+
+func (u *URL) checkHash(algo string, expected, content []byte) error {
+	h := newHash(algo)
+	h.Write(content)
+	if !bytes.Equal(h.Sum(nil), expected) {
+		return errors.New("content of `" + u.URL + "` does not match hash `" + u.Hash + "`")
+	}
+	return nil
+}
+
+func newHash(algo string) hash.Hash {
+	switch algo {
+	case "sha256":
+		return sha256.New()
+	case "sha384":
+		return sha512.New384()
+	}
+	return nil
+}
+
+// In creates a copy of the URL resource.
+// If the URL.CacheDir is relative, it is rewritten to be based in the path parameter.
+func (u *URL) In(path string) Resource {
+	clone := *u
+	if u.CacheDir != "" {
+		clone.CacheDir = helpers.RewritePath(path, u.CacheDir)
+	}
+	return &clone
+}
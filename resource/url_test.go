@@ -0,0 +1,119 @@
+package resource
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+func newTestServer(requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if r.URL.Path != "/lib.js" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("remote"))
+	}))
+}
+
+func sha256Hash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func TestDumpURL(t *testing.T) {
+	requests := 0
+	server := newTestServer(&requests)
+	defer server.Close()
+
+	u := NewURL(server.URL+"/lib.js", sha256Hash("remote"))
+	content, err := u.Dump()
+
+	if err != nil || !bytes.Equal(content, []byte("remote")) {
+		t.Error("error dumping URL Resource: ", err)
+	}
+
+	sum := sha512.Sum384([]byte("remote"))
+	u = NewURL(server.URL+"/lib.js", "sha384-"+base64.StdEncoding.EncodeToString(sum[:]))
+	content, err = u.Dump()
+
+	if err != nil || !bytes.Equal(content, []byte("remote")) {
+		t.Error("error dumping URL Resource with an integrity hash: ", err)
+	}
+
+	u = NewURL(server.URL+"/lib.js", sha256Hash("other"))
+	if _, err = u.Dump(); err == nil {
+		t.Error("content not matching the hash should return an error")
+	}
+
+	u = NewURL(server.URL+"/lib.js", "")
+	if _, err = u.Dump(); err == nil {
+		t.Error("missing hash should return an error")
+	}
+
+	u = NewURL(server.URL+"/lib.js", "md5:df54fa5f220b244f5ed919c871fe56f0")
+	if _, err = u.Dump(); err == nil {
+		t.Error("unsupported hash should return an error")
+	}
+
+	u = NewURL(server.URL+"/missing.js", sha256Hash("remote"))
+	if _, err = u.Dump(); err == nil {
+		t.Error("missing remote file should return an error")
+	}
+}
+
+func TestDumpURLCache(t *testing.T) {
+	requests := 0
+	server := newTestServer(&requests)
+	defer server.Close()
+
+	dir, _ := helpers.TempDir("", "statix_url_", "")
+	defer os.RemoveAll(dir)
+
+	u := NewURL(server.URL+"/lib.js", sha256Hash("remote"))
+	u.CacheDir = dir
+	u.Offline = true
+
+	if _, err := u.Dump(); err == nil {
+		t.Error("offline mode should fail if the file is not cached")
+	}
+
+	u.Offline = false
+	u.Dump()
+	u.Offline = true
+	content, err := u.Dump()
+
+	if err != nil || !bytes.Equal(content, []byte("remote")) {
+		t.Error("cached file should be used in offline mode: ", err)
+	}
+
+	u.Offline = false
+	u.Dump()
+
+	if requests != 1 {
+		t.Error("file should be downloaded once instead of ", requests)
+	}
+}
+
+func TestInURL(t *testing.T) {
+	u := NewURL("http://example.com/lib.js", "sha256:00")
+	u.CacheDir = "cache"
+	uModified := u.In("base").(*URL)
+
+	if uModified.CacheDir != "base/cache" || uModified.URL != u.URL || uModified.Hash != u.Hash {
+		t.Error("error while appling In to URL")
+	}
+
+	if u.CacheDir != "cache" {
+		t.Error("In should not alter original resources")
+	}
+}
@@ -0,0 +1,555 @@
+package statix
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sarulabs/statix/helpers"
+	"github.com/sarulabs/statix/resource"
+)
+
+// Paths of the companion files of a Sprite, used in Manager.URL.
+// The image of the Sprite is its empty path.
+const (
+	SpriteCSS  = "css"
+	SpriteJSON = "json"
+)
+
+// Sprite implements the Asset interface. It packs the PNG and SVG icons
+// located in Sprite.Input into one image written in the Sprite.Output file.
+// Sprite.Input is a directory or a pattern with the syntax of filepath.Match.
+// The format of the image is given by the extension of Sprite.Output:
+//   - a PNG sprite can only contain PNG icons,
+//   - an SVG sprite can contain SVG icons and PNG icons embedded as data URIs.
+//
+// If Sprite.CSS is defined, a stylesheet is written in this file. It contains
+// a rule for Sprite.Class (`icon` by default) with the image as background,
+// and a rule for each icon, named after the class and the icon (for example `.icon-home`).
+// If Sprite.JSON is defined, a json map with the position of each icon is written in this file.
+// Both files reference the image with its md5 suffix, with a path relative to their directory.
+// Sprite.Padding is the space in pixels between two icons.
+type Sprite struct {
+	Input   string
+	Output  string
+	CSS     string
+	JSON    string
+	Class   string
+	Padding int
+	Dumper  Dumper
+}
+
+// RewritePaths returns a new Sprite with updated paths.
+// More precisely, if the Sprite.Input is relative, it is prefixed by `input`,
+// and if Sprite.Output, Sprite.CSS or Sprite.JSON is relative, it is prefixed by `output`.
+// If Sprite.Dumper is nil, it is replaced by a FileDumper.
+func (sp Sprite) RewritePaths(input, output string) Asset {
+	rewrite := func(p string) string {
+		if p == "" {
+			return ""
+		}
+		return helpers.RewritePath(output, p)
+	}
+	return Sprite{
+		Input:   helpers.RewritePath(input, sp.Input),
+		Output:  rewrite(sp.Output),
+		CSS:     rewrite(sp.CSS),
+		JSON:    rewrite(sp.JSON),
+		Class:   sp.Class,
+		Padding: sp.Padding,
+		Dumper:  defaultDumper(sp.Dumper),
+	}
+}
+
+// Paths returns the paths of the files of the Sprite:
+// an empty path for the image, followed by SpriteCSS and SpriteJSON if they are defined.
+func (sp Sprite) Paths() []string {
+	paths := []string{""}
+	if sp.CSS != "" {
+		paths = append(paths, SpriteCSS)
+	}
+	if sp.JSON != "" {
+		paths = append(paths, SpriteJSON)
+	}
+	return paths
+}
+
+// OutputFile returns the absolute filename of a file of the Sprite
+// with a suffix added in the basename, like SingleAsset.OutputFile.
+// The `path` is one of the paths returned by Sprite.Paths.
+func (sp Sprite) OutputFile(path string, suffix string) (string, error) {
+	var out string
+
+	switch path {
+	case "":
+		out = sp.Output
+	case SpriteCSS:
+		out = sp.CSS
+	case SpriteJSON:
+		out = sp.JSON
+	}
+
+	if out == "" {
+		return "", fmt.Errorf("`%s` is not a file of the sprite", path)
+	}
+
+	out, err := filepath.Abs(out)
+	if err != nil {
+		return "", err
+	}
+	return helpers.AddFileSuffix(out, suffix), nil
+}
+
+// InputFiles returns the PNG and SVG files located in Sprite.Input, sorted by name.
+func (sp Sprite) InputFiles() ([]string, error) {
+	pattern := sp.Input
+	if info, err := os.Stat(sp.Input); err == nil && info.IsDir() {
+		pattern = filepath.Join(sp.Input, "*")
+	}
+
+	files, err := resource.NewGlob(pattern).Files()
+	if err != nil {
+		return nil, err
+	}
+
+	icons := []string{}
+	for _, f := range files {
+		switch strings.ToLower(filepath.Ext(f)) {
+		case ".png", ".svg":
+			icons = append(icons, f)
+		}
+	}
+	return icons, nil
+}
+
+// Dump packs the icons and dumps the image with the Sprite.Dumper.
+// Then the stylesheet and the json map are dumped, if they are defined.
+// The `filters` and `compressions` are applied like in SingleAsset.Dump.
+// The files of the returned DumpReport are in the order of Sprite.Paths.
+func (sp Sprite) Dump(filters []Filter, compressions []Compression) (DumpReport, error) {
+	report := DumpReport{}
+
+	icons, err := sp.icons()
+	if err != nil {
+		return report, err
+	}
+
+	width, height := packIcons(icons, sp.Padding)
+
+	var c []byte
+	if strings.EqualFold(filepath.Ext(sp.Output), ".svg") {
+		c = svgSprite(icons, width, height)
+	} else {
+		c, err = pngSprite(icons, width, height)
+	}
+	if err != nil {
+		return report, err
+	}
+
+	img, err := sp.dumpFile("", resource.NewBytes(c), filters, compressions)
+	if err != nil {
+		return report, err
+	}
+
+	report.Files = append(report.Files, img)
+
+	if sp.CSS != "" {
+		css := func(url string) ([]byte, error) {
+			return spriteCSS(icons, sp.class(), url), nil
+		}
+		f, err := sp.dumpCompanion(SpriteCSS, img.Filename, css, filters, compressions)
+		report.Files = append(report.Files, f)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if sp.JSON != "" {
+		m := func(url string) ([]byte, error) {
+			return spriteJSON(icons, url, width, height)
+		}
+		f, err := sp.dumpCompanion(SpriteJSON, img.Filename, m, filters, compressions)
+		report.Files = append(report.Files, f)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// dumpCompanion dumps the file `path` of the Sprite. Its content is returned
+// by `content` from the path of the `image` relative to the directory of the file.
+func (sp Sprite) dumpCompanion(path, image string, content func(string) ([]byte, error), filters []Filter, compressions []Compression) (DumpedFile, error) {
+	output, err := sp.OutputFile(path, "")
+	if err != nil {
+		return DumpedFile{}, err
+	}
+
+	url, err := relativePath(filepath.Dir(output), image)
+	if err != nil {
+		return DumpedFile{}, err
+	}
+
+	c, err := content(url)
+	if err != nil {
+		return DumpedFile{}, err
+	}
+
+	return sp.dumpFile(path, resource.NewBytes(c), filters, compressions)
+}
+
+// dumpFile dumps `r` in the file `path` of the Sprite.
+func (sp Sprite) dumpFile(path string, r resource.Resource, filters []Filter, compressions []Compression) (DumpedFile, error) {
+	output, err := sp.OutputFile(path, "")
+	if err != nil {
+		return DumpedFile{}, err
+	}
+
+	md5Output := func(hash string) (string, error) {
+		return sp.OutputFile(path, "."+hash)
+	}
+
+	return dumpFiltered(sp.Dumper, r, md5Output, output, filters, compressions)
+}
+
+// class returns Sprite.Class or `icon` if it is not defined.
+func (sp Sprite) class() string {
+	if sp.Class == "" {
+		return "icon"
+	}
+	return sp.Class
+}
+
+// spriteIcon is an icon packed in a Sprite.
+// The img of a PNG icon is decoded, and the root element of an SVG icon is parsed in svg.
+type spriteIcon struct {
+	name    string
+	content []byte
+	img     image.Image
+	svg     *resource.SVGElement
+	width   int
+	height  int
+	x       int
+	y       int
+}
+
+// iconNameRegexp matches the characters that can not be used in a css class.
+var iconNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// icons reads the icons of the Sprite. They are sorted by name.
+func (sp Sprite) icons() ([]*spriteIcon, error) {
+	files, err := sp.InputFiles()
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no icon found in " + sp.Input)
+	}
+
+	icons := []*spriteIcon{}
+	names := map[string]string{}
+
+	for _, f := range files {
+		base := filepath.Base(f)
+		name := iconNameRegexp.ReplaceAllString(base[:len(base)-len(filepath.Ext(base))], "-")
+
+		if previous, ok := names[name]; ok {
+			return nil, fmt.Errorf("`%s` and `%s` give the same icon name `%s`", previous, f, name)
+		}
+		names[name] = f
+
+		c, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+
+		icon := &spriteIcon{name: name, content: c}
+
+		if strings.EqualFold(filepath.Ext(f), ".svg") {
+			icon.svg, err = resource.ParseSVGElement(c)
+			if err == nil {
+				icon.width, icon.height, err = svgSize(c)
+			}
+		} else {
+			icon.img, err = png.Decode(bytes.NewReader(c))
+			if err == nil {
+				icon.width, icon.height = icon.img.Bounds().Dx(), icon.img.Bounds().Dy()
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", f, err)
+		}
+
+		icons = append(icons, icon)
+	}
+
+	return icons, nil
+}
+
+// packIcons sets the position of the `icons` and returns the size of the sprite.
+// The icons are placed in rows, from the tallest to the smallest.
+// The rows are about as wide as the sprite is tall.
+func packIcons(icons []*spriteIcon, padding int) (int, int) {
+	sorted := append([]*spriteIcon{}, icons...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].height > sorted[j].height
+	})
+
+	area, maxWidth := 0, 0
+	for _, icon := range sorted {
+		area += (icon.width + padding) * (icon.height + padding)
+		if icon.width > maxWidth {
+			maxWidth = icon.width
+		}
+	}
+	if side := int(math.Ceil(math.Sqrt(float64(area)))); side > maxWidth {
+		maxWidth = side
+	}
+
+	x, y, rowHeight, width := 0, 0, 0, 0
+
+	for _, icon := range sorted {
+		if x > 0 && x+icon.width > maxWidth {
+			x = 0
+			y += rowHeight + padding
+			rowHeight = 0
+		}
+
+		icon.x, icon.y = x, y
+		x += icon.width + padding
+
+		if icon.height > rowHeight {
+			rowHeight = icon.height
+		}
+		if icon.x+icon.width > width {
+			width = icon.x + icon.width
+		}
+	}
+
+	return width, y + rowHeight
+}
+
+// pngSprite draws the `icons` in a PNG image.
+func pngSprite(icons []*spriteIcon, width, height int) ([]byte, error) {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for _, icon := range icons {
+		if icon.img == nil {
+			return nil, fmt.Errorf("`%s` is an svg icon, it can only be packed in an svg sprite", icon.name)
+		}
+		r := image.Rect(icon.x, icon.y, icon.x+icon.width, icon.y+icon.height)
+		draw.Draw(dst, r, icon.img, icon.img.Bounds().Min, draw.Src)
+	}
+
+	buf := bytes.NewBuffer(nil)
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	err := encoder.Encode(buf, dst)
+	return buf.Bytes(), err
+}
+
+// svgSprite writes the `icons` in an SVG image. Each SVG icon
+// is nested in an <svg> element with its position and its size.
+// The ids used in several icons, and the references to them,
+// are prefixed by the name of the icon (see resource.PrefixSVGIDs).
+// The PNG icons are embedded in <image> elements.
+func svgSprite(icons []*spriteIcon, width, height int) []byte {
+	elements := map[*spriteIcon][]byte{}
+	idCount := map[string]int{}
+
+	for _, icon := range icons {
+		if icon.svg == nil {
+			continue
+		}
+		elements[icon] = svgElement(icon.svg)
+		for id := range resource.SVGIDs(elements[icon]) {
+			idCount[id]++
+		}
+	}
+
+	buf := bytes.NewBuffer(nil)
+
+	fmt.Fprintf(
+		buf,
+		`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, height, width, height,
+	)
+
+	for _, icon := range icons {
+		if icon.img != nil {
+			fmt.Fprintf(
+				buf,
+				`<image x="%d" y="%d" width="%d" height="%d" href="data:image/png;base64,%s"/>`+"\n",
+				icon.x, icon.y, icon.width, icon.height, base64.StdEncoding.EncodeToString(icon.content),
+			)
+			continue
+		}
+
+		conflicts := map[string]bool{}
+		for id := range resource.SVGIDs(elements[icon]) {
+			if idCount[id] > 1 {
+				conflicts[id] = true
+			}
+		}
+
+		fmt.Fprintf(buf, `<svg x="%d" y="%d" width="%d" height="%d">`, icon.x, icon.y, icon.width, icon.height)
+		buf.Write(resource.PrefixSVGIDs(elements[icon], icon.name, conflicts))
+		buf.WriteString("</svg>\n")
+	}
+
+	buf.WriteString("</svg>\n")
+	return buf.Bytes()
+}
+
+// svgElement writes the root <svg> element of an SVG icon,
+// without the xml declaration, the doctype and the comments around it.
+func svgElement(e *resource.SVGElement) []byte {
+	buf := bytes.NewBufferString("<svg")
+
+	for _, a := range e.Attrs {
+		name := a.Name.Local
+		if a.Name.Space != "" {
+			name = a.Name.Space + ":" + name
+		}
+		buf.WriteString(" " + name + `="`)
+		xml.EscapeText(buf, []byte(a.Value))
+		buf.WriteString(`"`)
+	}
+
+	buf.WriteString(">")
+	buf.Write(e.Content)
+	buf.WriteString("</svg>")
+
+	return buf.Bytes()
+}
+
+// svgSize returns the size of an SVG image in pixels. It is read in the
+// width and height attributes of the root element, or in its viewBox.
+func svgSize(c []byte) (int, int, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(c))
+
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return 0, 0, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local != "svg" {
+			return 0, 0, errors.New("the root element is not an svg element")
+		}
+
+		attrs := map[string]string{}
+		for _, a := range start.Attr {
+			attrs[a.Name.Local] = a.Value
+		}
+
+		width, errW := svgLength(attrs["width"])
+		height, errH := svgLength(attrs["height"])
+		if errW == nil && errH == nil {
+			return width, height, nil
+		}
+
+		box := strings.Fields(strings.Replace(attrs["viewBox"], ",", " ", -1))
+		if len(box) == 4 {
+			width, errW = svgLength(box[2])
+			height, errH = svgLength(box[3])
+			if errW == nil && errH == nil {
+				return width, height, nil
+			}
+		}
+
+		return 0, 0, errors.New("the size of the svg image is not defined")
+	}
+}
+
+// svgLength converts a length in pixels into an int, rounded up.
+func svgLength(s string) (int, error) {
+	f, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "px"), 64)
+	if err != nil {
+		return 0, err
+	}
+	if f <= 0 {
+		return 0, errors.New("the length should be positive")
+	}
+	return int(math.Ceil(f)), nil
+}
+
+// spriteCSS returns the stylesheet of a sprite.
+// The `url` is the url of the image relative to the stylesheet.
+func spriteCSS(icons []*spriteIcon, class, url string) []byte {
+	buf := bytes.NewBuffer(nil)
+
+	fmt.Fprintf(buf, ".%s {\n", class)
+	fmt.Fprintf(buf, "  background-image: url(\"%s\");\n", url)
+	buf.WriteString("  background-repeat: no-repeat;\n")
+	buf.WriteString("  display: inline-block;\n")
+	buf.WriteString("}\n")
+
+	for _, icon := range icons {
+		fmt.Fprintf(buf, "\n.%s-%s {\n", class, icon.name)
+		fmt.Fprintf(buf, "  background-position: %s %s;\n", cssOffset(icon.x), cssOffset(icon.y))
+		fmt.Fprintf(buf, "  width: %dpx;\n", icon.width)
+		fmt.Fprintf(buf, "  height: %dpx;\n", icon.height)
+		buf.WriteString("}\n")
+	}
+
+	return buf.Bytes()
+}
+
+// cssOffset returns the css length of a negative background offset.
+func cssOffset(n int) string {
+	if n == 0 {
+		return "0"
+	}
+	return "-" + strconv.Itoa(n) + "px"
+}
+
+// spriteMap is the json map of a Sprite.
+type spriteMap struct {
+	Image  string                   `json:"image"`
+	Width  int                      `json:"width"`
+	Height int                      `json:"height"`
+	Icons  map[string]spriteMapIcon `json:"icons"`
+}
+
+// spriteMapIcon is the position of an icon in the json map of a Sprite.
+type spriteMapIcon struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// spriteJSON returns the json map of a sprite.
+// The `url` is the url of the image relative to the map.
+func spriteJSON(icons []*spriteIcon, url string, width, height int) ([]byte, error) {
+	m := spriteMap{
+		Image:  url,
+		Width:  width,
+		Height: height,
+		Icons:  map[string]spriteMapIcon{},
+	}
+
+	for _, icon := range icons {
+		m.Icons[icon.name] = spriteMapIcon{X: icon.x, Y: icon.y, Width: icon.width, Height: icon.height}
+	}
+
+	return json.MarshalIndent(m, "", "  ")
+}
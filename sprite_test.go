@@ -0,0 +1,203 @@
+package statix
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestIcon(filename string, width, height int, c color.Color) {
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	buf := bytes.NewBuffer(nil)
+	png.Encode(buf, img)
+	ioutil.WriteFile(filename, buf.Bytes(), 0777)
+}
+
+func createSpriteFiles() {
+	os.MkdirAll("./tests/in/icons", 0777)
+	writeTestIcon("./tests/in/icons/red.png", 4, 4, color.NRGBA{R: 255, A: 255})
+	writeTestIcon("./tests/in/icons/blue arrow.png", 2, 6, color.NRGBA{B: 255, A: 255})
+	ioutil.WriteFile(
+		"./tests/in/icons/star.svg",
+		[]byte(`<?xml version="1.0"?>`+"\n"+`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 8"><path d="M0 0h10v8z"/></svg>`),
+		0777,
+	)
+}
+
+func getSpriteManagerTest() Manager {
+	m := getManagerTest()
+	m.Assets["icons"] = Sprite{
+		Input:   "icons/*.png",
+		Output:  "img/icons.png",
+		CSS:     "css/icons.css",
+		JSON:    "img/icons.json",
+		Padding: 1,
+	}
+	return m
+}
+
+func TestSpritePng(t *testing.T) {
+	removeTestFiles()
+	createSpriteFiles()
+	defer removeTestFiles()
+
+	m := getSpriteManagerTest()
+
+	report, err := m.Assets["icons"].RewritePaths(m.Input, m.Output).Dump(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 3 {
+		t.Fatal("the image, the css and the json map should be dumped: ", report.Files)
+	}
+
+	content, _ := ioutil.ReadFile("./tests/out/img/icons.json")
+	sm := spriteMap{}
+	if err = json.Unmarshal(content, &sm); err != nil {
+		t.Fatal(err)
+	}
+	if sm.Image != filepath.Base(report.Files[0].Filename) {
+		t.Error("the json map should reference the image with its md5 suffix: ", sm.Image)
+	}
+	if len(sm.Icons) != 2 || sm.Icons["blue-arrow"].Width != 2 || sm.Icons["blue-arrow"].Height != 6 {
+		t.Error("wrong icons in the json map: ", sm.Icons)
+	}
+
+	content, _ = ioutil.ReadFile("./tests/out/img/icons.png")
+	img, err := png.Decode(bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Bounds().Dx() != sm.Width || img.Bounds().Dy() != sm.Height {
+		t.Error("the size of the image should be the size in the json map: ", img.Bounds(), sm.Width, sm.Height)
+	}
+
+	red, blue := sm.Icons["red"], sm.Icons["blue-arrow"]
+	if r, _, _, _ := img.At(red.X+3, red.Y+3).RGBA(); r != 0xffff {
+		t.Error("the red icon is not at its position")
+	}
+	if _, _, b, _ := img.At(blue.X+1, blue.Y+5).RGBA(); b != 0xffff {
+		t.Error("the blue icon is not at its position")
+	}
+
+	content, _ = ioutil.ReadFile("./tests/out/css/icons.css")
+	css := string(content)
+	if !strings.Contains(css, `url("../img/`+filepath.Base(report.Files[0].Filename)+`")`) {
+		t.Error("the css should reference the image with its md5 suffix: ", css)
+	}
+	if !strings.Contains(css, ".icon-red {") || !strings.Contains(css, ".icon-blue-arrow {") {
+		t.Error("the css should contain a rule for each icon: ", css)
+	}
+
+	m.Assets["icons"] = Sprite{Input: "icons", Output: "img/icons.png"}
+	if _, err = m.Assets["icons"].RewritePaths(m.Input, m.Output).Dump(nil, nil); err == nil {
+		t.Error("an svg icon can not be packed in a png sprite")
+	}
+}
+
+func TestSpriteSvg(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	createSpriteFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Assets["icons"] = Sprite{Input: "icons", Output: "icons.svg", JSON: "icons.json", Class: "i"}
+
+	if _, err := m.Dump(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := ioutil.ReadFile("./tests/out/icons.svg")
+	svg := string(content)
+	if !strings.Contains(svg, `width="10" height="8"><svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 10 8">`) {
+		t.Error("the svg icons should be nested with their size: ", svg)
+	}
+	if strings.Contains(svg, "<?xml") || strings.Count(svg, "data:image/png;base64,") != 2 {
+		t.Error("the png icons should be embedded: ", svg)
+	}
+
+	if url := m.URL("icons", SpriteJSON); !strings.HasPrefix(url, "http://www.example.com/static/icons.") {
+		t.Error("wrong url for the json map: ", url)
+	}
+	if url := m.URL("icons", SpriteCSS); url != "" {
+		t.Error("the sprite has no css: ", url)
+	}
+}
+
+func TestSpriteSvgConflictingIds(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	os.MkdirAll("./tests/in/icons", 0777)
+	ioutil.WriteFile(
+		"./tests/in/icons/a.svg",
+		[]byte(`<!-- an <svg> icon --><svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 4 4"><defs><linearGradient id="g"/></defs><rect fill="url(#g)" width="4" height="4"/></svg>`),
+		0777,
+	)
+	ioutil.WriteFile(
+		"./tests/in/icons/b.svg",
+		[]byte(`<!DOCTYPE svg><svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" viewBox="0 0 4 4"><path id="g" d="M0 0h4v4z"/><use xlink:href="#g"/><g id="only-b"/></svg>`),
+		0777,
+	)
+
+	m := getManagerTest()
+	m.Assets["icons"] = Sprite{Input: "icons", Output: "icons.svg"}
+
+	if _, err := m.Dump(); err != nil {
+		t.Fatal(err)
+	}
+
+	content, _ := ioutil.ReadFile("./tests/out/icons.svg")
+	svg := string(content)
+
+	for _, s := range []string{`<linearGradient id="a-g"/>`, `fill="url(#a-g)"`, `<path id="b-g"`, `xlink:href="#b-g"`, `<g id="only-b"/>`} {
+		if !strings.Contains(svg, s) {
+			t.Error("the ids used in several icons should be prefixed by the icon name, missing ", s, ": ", svg)
+		}
+	}
+	if strings.Contains(svg, "<!--") || strings.Contains(svg, "DOCTYPE") {
+		t.Error("the comments and the doctype should not be copied: ", svg)
+	}
+}
+
+func TestDevHandlerSprite(t *testing.T) {
+	removeTestFiles()
+	createSpriteFiles()
+	defer removeTestFiles()
+
+	m := getSpriteManagerTest()
+	m.Dev = true
+
+	w := httptest.NewRecorder()
+	m.DevHandler().ServeHTTP(w, httptest.NewRequest("GET", "/static/css/icons.css", nil))
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "../img/icons.") {
+		t.Fatal("the dev handler should serve the css of the sprite: ", w.Code, w.Body.String())
+	}
+
+	image := w.Body.String()
+	image = image[strings.Index(image, "../img/")+3 : strings.Index(image, `")`)]
+
+	w = httptest.NewRecorder()
+	m.DevHandler().ServeHTTP(w, httptest.NewRequest("GET", "/static/"+image, nil))
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "image/png" {
+		t.Error("the dev handler should serve the image referenced by the css: ", w.Code, image)
+	}
+}
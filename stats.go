@@ -0,0 +1,471 @@
+package statix
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+// DumpStats describes a dump made with Manager.DumpStats.
+// Duration is the duration of the whole dump, manifest included.
+// Output is the absolute output directory of the Manager.
+// Assets contains the AssetStats in the order the assets were dumped.
+// Alterations contains the time spent in each type of alteration.
+type DumpStats struct {
+	Duration    time.Duration
+	Output      string
+	Assets      []AssetStats
+	Alterations []AlterationStats
+}
+
+// AssetStats describes the dump of an asset.
+// Files contains the DumpedFiles of the asset, with their sizes.
+type AssetStats struct {
+	Name     string
+	Duration time.Duration
+	Files    []DumpedFile
+}
+
+// AlterationStats is the time spent in the Alter method of an alteration type.
+// Name is the type of the alteration, for example "alteration.UglifyJs".
+// Calls is the number of times the Alter method was called.
+type AlterationStats struct {
+	Name     string
+	Calls    int
+	Duration time.Duration
+}
+
+// CacheHits returns the number of files of the asset that were
+// already up to date and that were not written again.
+func (as AssetStats) CacheHits() int {
+	hits := 0
+	for _, f := range as.Files {
+		if f.Status == Unchanged {
+			hits++
+		}
+	}
+	return hits
+}
+
+// Sizes returns the sum of the InputSizes and the sum of the Sizes of the files
+// of the asset. The input size is -1 if the InputSize of a file is unknown.
+func (as AssetStats) Sizes() (int64, int64) {
+	var input, output int64
+	for _, f := range as.Files {
+		if f.InputSize < 0 || input < 0 {
+			input = -1
+		} else {
+			input += f.InputSize
+		}
+		output += f.Size
+	}
+	return input, output
+}
+
+// Files returns the number of dumped files.
+func (s DumpStats) Files() int {
+	n := 0
+	for _, as := range s.Assets {
+		n += len(as.Files)
+	}
+	return n
+}
+
+// CacheHits returns the number of dumped files that were already up to date.
+func (s DumpStats) CacheHits() int {
+	hits := 0
+	for _, as := range s.Assets {
+		hits += as.CacheHits()
+	}
+	return hits
+}
+
+// jsonDumpStats is the json representation of DumpStats.
+// The durations are in milliseconds.
+type jsonDumpStats struct {
+	DurationMs  float64               `json:"duration_ms"`
+	Files       int                   `json:"files"`
+	CacheHits   int                   `json:"cache_hits"`
+	Assets      []jsonAssetStats      `json:"assets"`
+	Alterations []jsonAlterationStats `json:"alterations"`
+}
+
+type jsonAssetStats struct {
+	Name       string           `json:"name"`
+	DurationMs float64          `json:"duration_ms"`
+	CacheHits  int              `json:"cache_hits"`
+	Files      []jsonDumpedFile `json:"files"`
+}
+
+type jsonDumpedFile struct {
+	Symlink    string                    `json:"symlink"`
+	Filename   string                    `json:"filename"`
+	Status     string                    `json:"status"`
+	CacheHit   bool                      `json:"cache_hit"`
+	InputSize  int64                     `json:"input_size"`
+	Size       int64                     `json:"size"`
+	Ratio      float64                   `json:"ratio,omitempty"`
+	Compressed map[string]jsonCompressed `json:"compressed,omitempty"`
+}
+
+type jsonCompressed struct {
+	Size  int64   `json:"size"`
+	Ratio float64 `json:"ratio"`
+}
+
+type jsonAlterationStats struct {
+	Name       string  `json:"name"`
+	Calls      int     `json:"calls"`
+	DurationMs float64 `json:"duration_ms"`
+}
+
+// JSON returns the json representation of the DumpStats.
+// The paths of the files are relative to DumpStats.Output,
+// the durations are in milliseconds and the sizes in bytes.
+// An unknown input size is -1.
+func (s DumpStats) JSON() ([]byte, error) {
+	js := jsonDumpStats{
+		DurationMs:  milliseconds(s.Duration),
+		Files:       s.Files(),
+		CacheHits:   s.CacheHits(),
+		Assets:      []jsonAssetStats{},
+		Alterations: []jsonAlterationStats{},
+	}
+
+	for _, as := range s.Assets {
+		ja := jsonAssetStats{
+			Name:       as.Name,
+			DurationMs: milliseconds(as.Duration),
+			CacheHits:  as.CacheHits(),
+			Files:      []jsonDumpedFile{},
+		}
+
+		for _, f := range as.Files {
+			jf := jsonDumpedFile{
+				Symlink:   s.relative(f.Symlink),
+				Filename:  s.relative(f.Filename),
+				Status:    f.Status.String(),
+				CacheHit:  f.Status == Unchanged,
+				InputSize: f.InputSize,
+				Size:      f.Size,
+				Ratio:     f.Ratio(),
+			}
+			for ext, size := range f.CompressedSizes {
+				if jf.Compressed == nil {
+					jf.Compressed = map[string]jsonCompressed{}
+				}
+				jf.Compressed[ext] = jsonCompressed{Size: size, Ratio: f.CompressionRatio(ext)}
+			}
+			ja.Files = append(ja.Files, jf)
+		}
+
+		js.Assets = append(js.Assets, ja)
+	}
+
+	for _, as := range s.Alterations {
+		js.Alterations = append(js.Alterations, jsonAlterationStats{
+			Name:       as.Name,
+			Calls:      as.Calls,
+			DurationMs: milliseconds(as.Duration),
+		})
+	}
+
+	return json.MarshalIndent(js, "", "  ")
+}
+
+// WriteTable writes the DumpStats in `w` as human-readable tables:
+// one line for each asset, one line for each file and one line for each alteration.
+// The ratio of a file is its size divided by its input size,
+// and the ratio of a compressed sibling is its size divided by the size of the file.
+func (s DumpStats) WriteTable(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ASSET\tFILES\tCACHE HITS\tINPUT\tOUTPUT\tTIME")
+	for _, as := range s.Assets {
+		input, output := as.Sizes()
+		fmt.Fprintf(
+			tw, "%s\t%d\t%d\t%s\t%s\t%s\n",
+			as.Name, len(as.Files), as.CacheHits(), formatSize(input), formatSize(output), formatDuration(as.Duration),
+		)
+	}
+
+	fmt.Fprintln(tw, "\nFILE\tSTATUS\tINPUT\tOUTPUT\tRATIO\tCOMPRESSED")
+	for _, as := range s.Assets {
+		for _, f := range as.Files {
+			fmt.Fprintf(
+				tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				s.relative(f.Symlink), f.Status, formatSize(f.InputSize), formatSize(f.Size), formatRatio(f.Ratio()), compressedColumn(f),
+			)
+		}
+	}
+
+	if len(s.Alterations) > 0 {
+		fmt.Fprintln(tw, "\nALTERATION\tCALLS\tTIME")
+		for _, as := range s.Alterations {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", as.Name, as.Calls, formatDuration(as.Duration))
+		}
+	}
+
+	fmt.Fprintf(tw, "\n%d files, %d cache hits in %s\n", s.Files(), s.CacheHits(), formatDuration(s.Duration))
+
+	return tw.Flush()
+}
+
+// relative returns `filename` relative to DumpStats.Output, with slashes.
+// If it is not possible, `filename` is returned as it is.
+func (s DumpStats) relative(filename string) string {
+	if s.Output == "" || filename == "" {
+		return filename
+	}
+	rel, err := filepath.Rel(s.Output, filename)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filename
+	}
+	return filepath.ToSlash(rel)
+}
+
+// compressedColumn returns the extensions and the ratios of the compressed siblings of `f`.
+func compressedColumn(f DumpedFile) string {
+	exts := []string{}
+	for ext := range f.CompressedSizes {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	columns := []string{}
+	for _, ext := range exts {
+		columns = append(columns, ext+" "+formatRatio(f.CompressionRatio(ext)))
+	}
+	if len(columns) == 0 {
+		return "-"
+	}
+	return strings.Join(columns, " ")
+}
+
+// formatSize returns a human-readable size, or "-" if it is unknown.
+func formatSize(size int64) string {
+	switch {
+	case size < 0:
+		return "-"
+	case size < 1000:
+		return fmt.Sprintf("%d B", size)
+	case size < 1000*1000:
+		return fmt.Sprintf("%.1f kB", float64(size)/1000)
+	}
+	return fmt.Sprintf("%.1f MB", float64(size)/(1000*1000))
+}
+
+// formatRatio returns a ratio as a percentage, or "-" if it is unknown.
+func formatRatio(ratio float64) string {
+	if ratio == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", ratio*100)
+}
+
+// formatDuration rounds a duration to make it readable.
+func formatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(100 * time.Microsecond).String()
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// alterationTimer measures the time spent in the alterations of a Manager.
+type alterationTimer struct {
+	mu        sync.Mutex
+	durations map[string]*AlterationStats
+}
+
+func newAlterationTimer() *alterationTimer {
+	return &alterationTimer{durations: map[string]*AlterationStats{}}
+}
+
+// add records a call to the Alter method of the alteration type `name`.
+func (t *alterationTimer) add(name string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	as, ok := t.durations[name]
+	if !ok {
+		as = &AlterationStats{Name: name}
+		t.durations[name] = as
+	}
+	as.Calls++
+	as.Duration += d
+}
+
+// stats returns the AlterationStats, the slowest alteration first.
+func (t *alterationTimer) stats() []AlterationStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := []AlterationStats{}
+	for _, as := range t.durations {
+		stats = append(stats, *as)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Duration != stats[j].Duration {
+			return stats[i].Duration > stats[j].Duration
+		}
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
+
+// manager returns a copy of `m` in which the alterations
+// of the filters, the compressions and the assets are timed.
+func (t *alterationTimer) manager(m Manager) Manager {
+	filters := make([]Filter, len(m.Filters))
+	for i, f := range m.Filters {
+		f.Alteration = t.wrap(f.Alteration)
+		filters[i] = f
+	}
+	m.Filters = filters
+
+	compressions := make([]Compression, len(m.Compressions))
+	for i, c := range m.Compressions {
+		c.Alteration = t.wrap(c.Alteration)
+		compressions[i] = c
+	}
+	m.Compressions = compressions
+
+	assets := make(map[string]Asset, len(m.Assets))
+	for name, a := range m.Assets {
+		assets[name] = t.asset(a)
+	}
+	m.Assets = assets
+
+	return m
+}
+
+// asset returns a copy of `a` in which the alterations are timed.
+// The alterations of the AlteredResources of a SingleAsset are timed
+// if they are its input or if they are in a Collection.
+func (t *alterationTimer) asset(a Asset) Asset {
+	switch a := a.(type) {
+	case AssetPack:
+		alterations := make([]resource.Alteration, len(a.Alterations))
+		for i, alteration := range a.Alterations {
+			alterations[i] = t.wrap(alteration)
+		}
+		a.Alterations = alterations
+
+		variants := make([]Variant, len(a.Variants))
+		for i, v := range a.Variants {
+			v.Alteration = t.wrap(v.Alteration)
+			variants[i] = v
+		}
+		a.Variants = variants
+
+		alternatives := make([]Alternative, len(a.Alternatives))
+		for i, alt := range a.Alternatives {
+			alt.Alteration = t.wrap(alt.Alteration)
+			alternatives[i] = alt
+		}
+		a.Alternatives = alternatives
+
+		return a
+	case SingleAsset:
+		a.Input = t.resource(a.Input)
+		return a
+	}
+	return a
+}
+
+// resource returns a copy of `r` in which the alterations are timed.
+func (t *alterationTimer) resource(r resource.Resource) resource.Resource {
+	switch r := r.(type) {
+	case *resource.AlteredResource:
+		alterations := make([]resource.Alteration, len(r.Alterations))
+		for i, alteration := range r.Alterations {
+			alterations[i] = t.wrap(alteration)
+		}
+		return resource.NewAlteredResource(t.resource(r.Resource), alterations...)
+	case *resource.Collection:
+		resources := make([]resource.Resource, len(r.Resources))
+		for i, child := range r.Resources {
+			resources[i] = t.resource(child)
+		}
+		return resource.NewCollection(resources...)
+	}
+	return r
+}
+
+// wrap returns a timedAlteration measuring the time spent in `a`.
+// If `a` implements the resource.Renamer interface, the returned alteration
+// implements it too, so the names of the files are the same with and without timing.
+func (t *alterationTimer) wrap(a resource.Alteration) resource.Alteration {
+	if a == nil {
+		return nil
+	}
+	ta := timedAlteration{alteration: a, timer: t}
+	if _, ok := a.(resource.Renamer); ok {
+		return timedRenamer{ta}
+	}
+	return ta
+}
+
+// timedAlteration is an alteration measuring the time spent in the Alter method
+// of an other alteration.
+type timedAlteration struct {
+	alteration resource.Alteration
+	timer      *alterationTimer
+}
+
+// Alter applies the alteration and records its duration.
+// The lazy inputs are dumped before the clock starts, so the time spent
+// in the alterations of a nested AlteredResource is not counted twice.
+func (ta timedAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	if lazyResource(r) {
+		c, err := r.Dump()
+		if err != nil {
+			return &resource.Empty{}, err
+		}
+		r = &resource.Bytes{Content: c, Meta: resource.MetadataOf(r)}
+	}
+
+	start := time.Now()
+	altered, err := ta.alteration.Alter(r)
+	ta.timer.add(fmt.Sprintf("%T", ta.alteration), time.Since(start))
+	return altered, err
+}
+
+// timedRenamer is a timedAlteration that forwards
+// the Rename method of a resource.Renamer.
+type timedRenamer struct {
+	timedAlteration
+}
+
+// Rename applies the Rename method of the alteration.
+func (tr timedRenamer) Rename(name string) string {
+	return tr.alteration.(resource.Renamer).Rename(name)
+}
+
+// lazyResource checks if the content of `r` is altered when it is dumped,
+// because it is an AlteredResource or a Collection containing one.
+func lazyResource(r resource.Resource) bool {
+	switch r := r.(type) {
+	case *resource.AlteredResource:
+		return len(r.Alterations) > 0 || lazyResource(r.Resource)
+	case *resource.Collection:
+		for _, child := range r.Resources {
+			if lazyResource(child) {
+				return true
+			}
+		}
+	}
+	return false
+}
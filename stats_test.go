@@ -0,0 +1,189 @@
+package statix
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sarulabs/statix/alteration"
+	"github.com/sarulabs/statix/resource"
+)
+
+func TestManagerDumpStats(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	ioutil.WriteFile("./tests/in/dirIn/big.css", bytes.Repeat([]byte("body { color: red; }\n"), 100), 0777)
+
+	m := getManagerTest()
+	m.Compressions = []Compression{
+		{
+			Alteration: alteration.NewGzip(gzip.BestCompression),
+			Extension:  ".gz",
+			Pattern:    NewExtensionPattern("css"),
+		},
+	}
+
+	report, stats, err := m.DumpStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stats.Assets) != 2 || stats.Assets[0].Name != "pack" || stats.Assets[1].Name != "single" {
+		t.Fatal("the assets should be sorted by name: ", stats.Assets)
+	}
+	if stats.Files() != len(report.Files) || stats.Files() != 4 || stats.CacheHits() != 0 {
+		t.Error("wrong number of files or cache hits: ", stats.Files(), stats.CacheHits())
+	}
+
+	files := map[string]DumpedFile{}
+	for _, f := range stats.Assets[0].Files {
+		files[stats.relative(f.Symlink)] = f
+	}
+
+	if f := files["dirOut/subDir/a2.ext"]; f.InputSize != 7 || f.Size != 7 || f.Ratio() != 1 {
+		t.Error("wrong sizes for a2.ext: ", f.InputSize, f.Size)
+	}
+	css := files["dirOut/big.css"]
+	if css.InputSize != 2100 || css.Size != 2100 {
+		t.Error("wrong sizes for big.css: ", css.InputSize, css.Size)
+	}
+	if ratio := css.CompressionRatio(".gz"); ratio <= 0 || ratio >= 0.5 {
+		t.Error("big.css should be compressed: ", css.CompressedSizes)
+	}
+
+	calls := map[string]int{}
+	for _, as := range stats.Alterations {
+		calls[as.Name] = as.Calls
+	}
+	if calls["statix.ReverseAlteration"] != 2 || calls["alteration.Gzip"] != 1 {
+		t.Error("wrong alteration stats: ", stats.Alterations)
+	}
+
+	c, err := stats.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	js := jsonDumpStats{}
+	if err = json.Unmarshal(c, &js); err != nil {
+		t.Fatal(err)
+	}
+	if len(js.Assets) != 2 || js.Files != 4 || js.Assets[1].Files[0].Symlink != "single.ext" {
+		t.Error("wrong json stats: ", string(c))
+	}
+
+	buf := bytes.NewBuffer(nil)
+	if err = stats.WriteTable(buf); err != nil {
+		t.Fatal(err)
+	}
+	if table := buf.String(); !strings.Contains(table, "dirOut/big.css") || !strings.Contains(table, ".gz ") ||
+		!strings.Contains(table, "statix.ReverseAlteration") || !strings.Contains(table, "4 files, 0 cache hits") {
+		t.Error("wrong table: ", table)
+	}
+
+	// ReverseAlteration reverses the content of the single asset in place
+	m.Assets["single"] = getManagerTest().Assets["single"]
+
+	_, stats, err = m.DumpStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.CacheHits() != 4 {
+		t.Error("the second dump should only have cache hits: ", stats.CacheHits())
+	}
+}
+
+func TestDumpStatsRename(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Assets = map[string]Asset{
+		"pack": AssetPack{Input: "dirIn", Output: "dirOut", Alterations: []resource.Alteration{CompileAlteration{}}},
+	}
+
+	report, stats, err := m.DumpStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	symlinks := []string{}
+	for _, f := range report.Files {
+		symlinks = append(symlinks, stats.relative(f.Symlink))
+	}
+	sort.Strings(symlinks)
+
+	if strings.Join(symlinks, " ") != "dirOut/a1 dirOut/a1.map dirOut/subDir/a2.out dirOut/subDir/a2.out.map" {
+		t.Error("the timed alterations should rename the files and keep their extra outputs: ", symlinks)
+	}
+}
+
+// sleepAlteration waits before returning the content of the resource.
+type sleepAlteration time.Duration
+
+func (sa sleepAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	c, err := r.Dump()
+	time.Sleep(time.Duration(sa))
+	return resource.NewBytes(c), err
+}
+
+// copyAlteration returns the content of the resource.
+type copyAlteration struct{}
+
+func (ca copyAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	c, err := r.Dump()
+	return resource.NewBytes(c), err
+}
+
+func TestDumpStatsNestedAlterations(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Assets = map[string]Asset{
+		"single": SingleAsset{
+			Input: resource.NewAlteredResource(
+				resource.NewAlteredResource(resource.NewString("app;"), sleepAlteration(50*time.Millisecond)),
+				copyAlteration{},
+			),
+			Output: "app.js",
+		},
+	}
+
+	_, stats, err := m.DumpStats()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	durations := map[string]time.Duration{}
+	for _, as := range stats.Alterations {
+		durations[as.Name] = as.Duration
+	}
+	if durations["statix.sleepAlteration"] < 50*time.Millisecond {
+		t.Error("the nested alteration should be timed: ", stats.Alterations)
+	}
+	if durations["statix.copyAlteration"] >= 25*time.Millisecond {
+		t.Error("the time of the nested alteration should not be counted in the outer one: ", stats.Alterations)
+	}
+}
+
+func TestTimedAlterationRenamer(t *testing.T) {
+	timer := newAlterationTimer()
+
+	if _, ok := timer.wrap(copyAlteration{}).(resource.Renamer); ok {
+		t.Error("an alteration that does not rename the files should not implement resource.Renamer")
+	}
+
+	rn, ok := timer.wrap(CompileAlteration{}).(resource.Renamer)
+	if !ok || rn.Rename("a.ext") != "a.out" {
+		t.Error("the Rename method should be forwarded")
+	}
+}
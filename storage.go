@@ -0,0 +1,241 @@
+package statix
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/sarulabs/statix/helpers"
+)
+
+// Storage is the interface of the object storages used by a StorageDumper.
+// The keys are slash separated paths.
+type Storage interface {
+	Exists(key string) (bool, error)
+	Put(object StorageObject, r io.Reader) error
+}
+
+// StorageObject describes an object written in a Storage.
+type StorageObject struct {
+	Key             string
+	Size            int64
+	ContentType     string
+	ContentEncoding string
+	CacheControl    string
+}
+
+// contentEncodings maps the extensions of the compressed siblings
+// to the value of their Content-Encoding header.
+var contentEncodings = map[string]string{
+	".gz": "gzip",
+	".br": "br",
+}
+
+// StorageDumper implements the Dumper, StreamDumper, Unlinker, Sizer and Resolver interfaces
+// to upload the dumped files in an object Storage.
+// The dumped files should be located in StorageDumper.Root. The key of an object
+// is its path relative to the root, prefixed by StorageDumper.Prefix.
+//
+// The files with an md5 suffix never change. They are uploaded only if they do not
+// already exist in the storage, with the StorageDumper.CacheControl header.
+// The other files (for example the manifest) are always uploaded
+// with the StorageDumper.MutableCacheControl header.
+//
+// Object storages do not have symlinks. The symlinks are only kept in memory
+// to resolve the urls. Use a Manifest to find the objects from the names of the assets.
+// The Manager writes the manifest after all the assets, so a manifest
+// is never uploaded before the objects it points to.
+type StorageDumper struct {
+	Root                string
+	Prefix              string
+	Storage             Storage
+	CacheControl        string
+	MutableCacheControl string
+	mu                  sync.RWMutex
+	aliases             map[string]string
+}
+
+// NewStorageDumper creates a StorageDumper uploading the files located in `root`.
+// The files with an md5 suffix are cached for one year by the clients.
+// The other files need to be revalidated.
+func NewStorageDumper(root string, storage Storage) *StorageDumper {
+	return &StorageDumper{
+		Root:                root,
+		Storage:             storage,
+		CacheControl:        "public, max-age=31536000, immutable",
+		MutableCacheControl: "no-cache",
+		aliases:             map[string]string{},
+	}
+}
+
+// Dump uploads `data` in the object matching `filename`.
+// If `filename` has an md5 suffix and already exists in the storage,
+// nothing is uploaded and the returned status is Unchanged.
+func (sd *StorageDumper) Dump(filename, symlink string, data []byte) (DumpStatus, error) {
+	return sd.dump(filename, symlink, bytes.NewReader(data), int64(len(data)))
+}
+
+// DumpReader works like Dump but the content is read from `r`.
+// The filename is returned by `name` from the md5 hash of the content.
+// The content is copied in a temporary file while its hash is computed,
+// and it is uploaded from this file, so it is not loaded in memory.
+func (sd *StorageDumper) DumpReader(name func(string) (string, error), symlink string, r io.Reader) (string, DumpStatus, error) {
+	h := md5.New()
+
+	tmp, err := helpers.WriteTempFile("", io.TeeReader(r, h))
+	if err != nil {
+		return "", Created, err
+	}
+	defer os.Remove(tmp)
+
+	filename, err := name(hex.EncodeToString(h.Sum(nil)))
+	if err != nil {
+		return "", Created, err
+	}
+
+	f, err := os.Open(tmp)
+	if err != nil {
+		return filename, Created, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return filename, Created, err
+	}
+
+	status, err := sd.dump(filename, symlink, f, info.Size())
+	return filename, status, err
+}
+
+// dump uploads the `size` bytes of `r` in the object matching `filename`.
+func (sd *StorageDumper) dump(filename, symlink string, r io.Reader, size int64) (DumpStatus, error) {
+	key, err := sd.key(filename)
+	if err != nil {
+		return Created, err
+	}
+
+	if symlink != "" {
+		if _, err = sd.key(symlink); err != nil {
+			return Created, err
+		}
+	}
+
+	exists, err := sd.Storage.Exists(key)
+	if err != nil {
+		return Created, err
+	}
+
+	immutable := md5FileRegexp.MatchString(filename)
+
+	if !exists || !immutable {
+		err = sd.put(key, filename, immutable, r, size)
+		if err != nil {
+			return Created, err
+		}
+	}
+
+	sd.mu.Lock()
+	if sd.aliases == nil {
+		sd.aliases = map[string]string{}
+	}
+	if symlink != "" {
+		sd.aliases[filepath.Clean(symlink)] = filepath.Clean(filename)
+	}
+	sd.mu.Unlock()
+
+	switch {
+	case !exists:
+		return Created, nil
+	case immutable:
+		return Unchanged, nil
+	}
+	return Updated, nil
+}
+
+// put uploads the `size` bytes of `r` in the object `key`.
+func (sd *StorageDumper) put(key, filename string, immutable bool, r io.Reader, size int64) error {
+	object := StorageObject{
+		Key:          key,
+		Size:         size,
+		CacheControl: sd.MutableCacheControl,
+	}
+	if immutable {
+		object.CacheControl = sd.CacheControl
+	}
+
+	name := filepath.Base(filename)
+	if encoding, ok := contentEncodings[filepath.Ext(name)]; ok {
+		object.ContentEncoding = encoding
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	object.ContentType = mime.TypeByExtension(filepath.Ext(name))
+	if object.ContentType == "" {
+		object.ContentType = "application/octet-stream"
+	}
+
+	return sd.Storage.Put(object, r)
+}
+
+// Resolve returns the filename of the file `symlink` points to.
+// Only the symlinks dumped by this StorageDumper can be resolved.
+func (sd *StorageDumper) Resolve(symlink string) (string, error) {
+	sd.mu.RLock()
+	defer sd.mu.RUnlock()
+
+	filename, ok := sd.aliases[filepath.Clean(symlink)]
+	if !ok {
+		return "", errors.New(symlink + " has not been dumped")
+	}
+	return filename, nil
+}
+
+// Size checks if the object matching `filename` exists in the storage.
+// Its size is unknown, so -1 is returned.
+func (sd *StorageDumper) Size(filename string) (int64, bool) {
+	key, err := sd.key(filename)
+	if err != nil {
+		return -1, false
+	}
+	exists, err := sd.Storage.Exists(key)
+	return -1, err == nil && exists
+}
+
+// Unlink forgets the alias `symlink`, so it can not be resolved anymore.
+// No object is removed from the storage.
+func (sd *StorageDumper) Unlink(symlink string) error {
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+
+	delete(sd.aliases, filepath.Clean(symlink))
+
+	return nil
+}
+
+// key returns the key of the object matching `filename`.
+func (sd *StorageDumper) key(filename string) (string, error) {
+	root, err := filepath.Abs(sd.Root)
+	if err != nil {
+		return "", err
+	}
+
+	filename, err = filepath.Abs(filename)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(root, filename)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", errors.New(filename + " is not located in " + sd.Root)
+	}
+
+	return strings.TrimPrefix(path.Join(sd.Prefix, filepath.ToSlash(rel)), "/"), nil
+}
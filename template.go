@@ -0,0 +1,204 @@
+package statix
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+// TemplateFuncs returns the functions bound to the Manager that can be used
+// in a resource.Template (or in any text/template or html/template):
+// - asset_url returns the url of an asset. It takes the same arguments as Manager.URL.
+//
+// asset_url does not read the symlinks. It builds the asset without writing it
+// to know the md5 hash of its content, so the url is correct even if the asset
+// has not been dumped yet. If Manager.Dev is true, it returns the development url.
+// If an asset references itself, directly or through the templates of other assets
+// using the same functions, asset_url returns an error instead of building it forever.
+//
+// The urls are not memoized, because the assets may change between two renders.
+// Use Manager.TemplateScope to build each asset only once per render.
+func (m Manager) TemplateFuncs() template.FuncMap {
+	return (&templateResolver{manager: m, active: &activeURLs{}}).funcs()
+}
+
+// TemplateScope returns a resource.FuncsScope creating the functions of
+// Manager.TemplateFuncs for each execution of a resource.Template.
+// During an execution, the urls are memoized, so each asset is built only once.
+// The cycles are detected across all the templates using the same TemplateScope.
+func (m Manager) TemplateScope() resource.FuncsScope {
+	return templateScope{manager: m, active: &activeURLs{}}
+}
+
+type templateScope struct {
+	manager Manager
+	active  *activeURLs
+}
+
+// Funcs returns the functions of one execution, with their own memo.
+func (ts templateScope) Funcs() template.FuncMap {
+	return (&templateResolver{manager: ts.manager, active: ts.active, memo: map[string]string{}}).funcs()
+}
+
+// templateResolver implements the asset_url function.
+// The urls being computed are in `active`, so the cycles can be detected.
+// If `memo` is not nil, the computed urls are stored in it.
+type templateResolver struct {
+	manager Manager
+	active  *activeURLs
+	mu      sync.Mutex
+	memo    map[string]string
+}
+
+func (r *templateResolver) funcs() template.FuncMap {
+	return template.FuncMap{
+		"asset_url": r.assetURL,
+	}
+}
+
+func (r *templateResolver) assetURL(assetName string, paths ...string) (string, error) {
+	key := strings.Join(append([]string{assetName}, paths...), "\x00")
+
+	if url, ok := r.memoized(key); ok {
+		return url, nil
+	}
+
+	if !r.active.start(key) {
+		return "", fmt.Errorf("asset `%s` references itself with asset_url", assetName)
+	}
+	defer r.active.done(key)
+
+	url, err := r.manager.templateURL(assetName, paths...)
+	if err != nil {
+		return "", err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.memo != nil {
+		r.memo[key] = url
+	}
+
+	return url, nil
+}
+
+func (r *templateResolver) memoized(key string) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	url, ok := r.memo[key]
+	return url, ok
+}
+
+// activeURLs is the set of the urls being computed by asset_url.
+// The key of an url is the name of the asset followed by its paths.
+type activeURLs struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+// start adds `key` to the set. It returns false if it was already in it.
+func (a *activeURLs) start(key string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.keys == nil {
+		a.keys = map[string]bool{}
+	}
+	if a.keys[key] {
+		return false
+	}
+	a.keys[key] = true
+	return true
+}
+
+func (a *activeURLs) done(key string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.keys, key)
+}
+
+func (m Manager) templateURL(assetName string, paths ...string) (string, error) {
+	if m.Dev {
+		symlink, err := m.Symlink(assetName, paths...)
+		if err != nil {
+			return "", err
+		}
+		return m.URLFromFilename(symlink)
+	}
+
+	filename, err := m.plannedFilename(assetName, paths...)
+	if err != nil {
+		return "", err
+	}
+
+	return m.URLFromFilename(filename)
+}
+
+// plannedFilename returns the filename (with the md5 suffix)
+// an asset would have if it was dumped now.
+func (m Manager) plannedFilename(assetName string, paths ...string) (string, error) {
+	a, ok := m.Assets[assetName]
+	if !ok {
+		return "", fmt.Errorf("asset `%s` does not exist", assetName)
+	}
+
+	input, err := filepath.Abs(m.Input)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := filepath.Abs(m.Output)
+	if err != nil {
+		return "", err
+	}
+
+	planner := m
+	planner.Dumper = PlanDumper{}
+
+	switch a := planner.rewriteAsset(a, input, output).(type) {
+	case AssetPack:
+		path := ""
+		if len(paths) > 0 {
+			path = paths[0]
+		}
+		filename, v, ok := a.source(path)
+		if !ok {
+			return "", fmt.Errorf("`%s` is not a file of asset `%s`", path, assetName)
+		}
+		var report DumpReport
+		if v != nil {
+			report, err = a.DumpVariant(filename, *v, m.Filters, nil)
+		} else {
+			report, err = a.DumpFile(filename, m.Filters, nil)
+		}
+		if err != nil {
+			return "", err
+		}
+		return report.Files[0].Filename, nil
+	case SingleAsset:
+		report, err := a.Dump(m.Filters, nil)
+		if err != nil {
+			return "", err
+		}
+		return report.Files[0].Filename, nil
+	case Sprite:
+		symlink, err := m.Symlink(assetName, paths...)
+		if err != nil {
+			return "", err
+		}
+		report, err := a.Dump(m.Filters, nil)
+		if err != nil {
+			return "", err
+		}
+		for _, f := range report.Files {
+			if f.Symlink == symlink {
+				return f.Filename, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("the url of asset `%s` can not be computed", assetName)
+}
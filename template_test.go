@@ -0,0 +1,133 @@
+package statix
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+func TestManagerTemplateFuncs(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Assets["config"] = SingleAsset{
+		Output: "config.js",
+		Input: resource.NewTemplate(
+			resource.NewString(`var env = "{{.}}"; var single = "{{asset_url "single"}}"; var a1 = "{{asset_url "pack" "a1"}}";`),
+			"prod",
+			m.TemplateFuncs(),
+		),
+	}
+
+	_, err := m.Dump()
+	if err != nil {
+		t.Error(err)
+	}
+
+	content, err := ioutil.ReadFile("./tests/out/config.js")
+	expected := `var env = "prod"; ` +
+		`var single = "http://www.example.com/static/single.b34c31dcb721861cd51bfa6f3d850524.ext"; ` +
+		`var a1 = "http://www.example.com/static/dirOut/a1.df54fa5f220b244f5ed919c871fe56f0";`
+	if err != nil || string(content) != expected {
+		t.Error("config.js should contain ", expected, " instead of ", string(content))
+	}
+
+	m.Dev = true
+	url, err := m.TemplateFuncs()["asset_url"].(func(string, ...string) (string, error))("single")
+	if err != nil || url != "http://www.example.com/static/single.ext" {
+		t.Error("asset_url should return the development url instead of ", url)
+	}
+
+	_, err = m.TemplateFuncs()["asset_url"].(func(string, ...string) (string, error))("missing")
+	if err == nil {
+		t.Error("asset_url should return an error for a missing asset")
+	}
+}
+
+// countingResource counts the calls to its Dump method.
+type countingResource struct {
+	calls *int
+}
+
+func (cr countingResource) Dump() ([]byte, error) {
+	*cr.calls++
+	return []byte("counted"), nil
+}
+
+func (cr countingResource) In(path string) resource.Resource {
+	return cr
+}
+
+func TestManagerTemplateScope(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	calls := 0
+
+	m := getManagerTest()
+	m.Assets["counted"] = SingleAsset{Output: "counted.js", Input: countingResource{calls: &calls}}
+
+	source := resource.NewString(`{{asset_url "counted"}} {{asset_url "counted"}} {{asset_url "counted"}}`)
+
+	tmpl := resource.NewTemplate(source, nil, nil)
+	tmpl.Scope = m.TemplateScope()
+
+	for i := 1; i <= 2; i++ {
+		calls = 0
+		content, err := tmpl.Dump()
+		if err != nil || !strings.HasPrefix(string(content), "http://www.example.com/static/counted.") {
+			t.Fatal("the template should contain the urls: ", string(content), err)
+		}
+		if calls != 1 {
+			t.Error("the asset should be built once per render: ", calls)
+		}
+	}
+
+	calls = 0
+	if _, err := resource.NewTemplate(source, nil, m.TemplateFuncs()).Dump(); err != nil || calls != 3 {
+		t.Error("the urls of TemplateFuncs should not be memoized: ", calls, err)
+	}
+}
+
+func TestManagerTemplateCycles(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getManagerTest()
+	m.Assets = map[string]Asset{}
+	scope := m.TemplateScope()
+	funcs := m.TemplateFuncs()
+
+	self := resource.NewTemplate(resource.NewString(`{{asset_url "self"}}`), nil, nil)
+	self.Scope = scope
+	a := resource.NewTemplate(resource.NewString(`{{asset_url "b"}}`), nil, nil)
+	a.Scope = scope
+	b := resource.NewTemplate(resource.NewString(`{{asset_url "a"}}`), nil, nil)
+	b.Scope = scope
+
+	// the functions share the Assets map of the Manager
+	m.Assets["self"] = SingleAsset{Output: "self.js", Input: self}
+	if _, err := m.Dump(); err == nil || !strings.Contains(err.Error(), "asset `self` references itself") {
+		t.Error("an asset referencing itself should return an error instead of ", err)
+	}
+
+	delete(m.Assets, "self")
+	m.Assets["a"] = SingleAsset{Output: "a.js", Input: a}
+	m.Assets["b"] = SingleAsset{Output: "b.js", Input: b}
+	if _, err := m.Dump(); err == nil || !strings.Contains(err.Error(), "references itself") {
+		t.Error("a cycle between two assets should return an error instead of ", err)
+	}
+
+	delete(m.Assets, "a")
+	delete(m.Assets, "b")
+	m.Assets["self"] = SingleAsset{Output: "self.js", Input: resource.NewTemplate(resource.NewString(`{{asset_url "self"}}`), nil, funcs)}
+	if _, err := m.Dump(); err == nil || !strings.Contains(err.Error(), "asset `self` references itself") {
+		t.Error("TemplateFuncs should detect the cycles instead of ", err)
+	}
+}
@@ -0,0 +1,59 @@
+package statix
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/sarulabs/statix/helpers"
+	"github.com/sarulabs/statix/resource"
+)
+
+// Variant is an additional output of the files of an AssetPack,
+// for example a resized image. It is dumped for each file matching
+// Variant.Pattern, with Variant.Alteration applied to its content.
+// The name of the variant is the name of the file with Variant.Suffix
+// added before the extension (and before the md5 suffix). For example
+// the variant of `photo.jpg` with the suffix `-320w` is `photo-320w.jpg`.
+// If Variant.Descriptor is defined, the variant is part of the srcset
+// returned by Manager.Srcset, with this descriptor (for example `320w`).
+type Variant struct {
+	Suffix     string
+	Descriptor string
+	Pattern    Pattern
+	Alteration resource.Alteration
+}
+
+// Srcset returns the value of the srcset attribute of an image of an AssetPack.
+// The `path` is the path of the image inside the output directory, like in Manager.URL.
+// The srcset contains the url of each variant of the image that has a Variant.Descriptor.
+// If an error occurs, an empty string is returned.
+func (m Manager) Srcset(assetName string, path string) string {
+	srcset, _ := m.srcset(assetName, path)
+	return srcset
+}
+
+func (m Manager) srcset(assetName string, path string) (string, error) {
+	ap, ok := m.Assets[assetName].(AssetPack)
+	if !ok {
+		return "", errors.New("asset `" + assetName + "` is not an AssetPack")
+	}
+
+	filename := ap.RewritePaths(m.Input, m.Output).(AssetPack).InputFile(path)
+
+	candidates := []string{}
+
+	for _, v := range ap.Variants {
+		if v.Descriptor == "" || !v.Pattern.Match(filename) {
+			continue
+		}
+
+		url := m.URL(assetName, helpers.AddFileSuffix(path, v.Suffix))
+		if url == "" {
+			return "", errors.New("no url for the variant `" + v.Suffix + "` of `" + path + "`")
+		}
+
+		candidates = append(candidates, url+" "+v.Descriptor)
+	}
+
+	return strings.Join(candidates, ", "), nil
+}
@@ -0,0 +1,99 @@
+package statix
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sarulabs/statix/resource"
+)
+
+// SuffixAlteration is an alteration that appends a string to the content of a resource.
+type SuffixAlteration string
+
+func (sa SuffixAlteration) Alter(r resource.Resource) (resource.Resource, error) {
+	c, err := r.Dump()
+	return resource.NewBytes(append(append([]byte{}, c...), sa...)), err
+}
+
+func getVariantManagerTest() Manager {
+	m := getManagerTest()
+	m.Assets["pack"] = AssetPack{
+		Input:  "dirIn",
+		Output: "dirOut",
+		Variants: []Variant{
+			{Suffix: "-small", Descriptor: "320w", Pattern: NewExtensionPattern("ext"), Alteration: SuffixAlteration("-small")},
+			{Suffix: "-large", Descriptor: "1280w", Pattern: NewExtensionPattern("ext"), Alteration: SuffixAlteration("-large")},
+			{Suffix: "-copy", Alteration: SuffixAlteration("")},
+		},
+	}
+	return m
+}
+
+func TestAssetPackVariants(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getVariantManagerTest()
+
+	report, err := m.Dump()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Files) != 7 {
+		t.Error("a1 should have 1 variant and a2.ext 3 variants: ", len(report.Files))
+	}
+
+	content, err := ioutil.ReadFile("./tests/out/dirOut/subDir/a2-small.ext")
+	if err != nil || !bytes.Equal(content, []byte("llams-2a-kcap")) {
+		t.Error("the variant alteration should be applied before the filters: ", string(content))
+	}
+
+	content, err = ioutil.ReadFile("./tests/out/dirOut/a1-copy")
+	if err != nil || !bytes.Equal(content, []byte("pack-a1")) {
+		t.Error("wrong content for a1-copy: ", string(content))
+	}
+
+	paths, err := m.Assets["pack"].RewritePaths(m.Input, m.Output).(AssetPack).OutputPaths()
+	if err != nil || len(paths) != 6 {
+		t.Error("the output paths should contain the variants: ", paths, err)
+	}
+
+	srcset := m.Srcset("pack", "subDir/a2.ext")
+	expected := "http://www.example.com/static/dirOut/subDir/a2-small.39fd4b26b50865f33a559cced95685d0.ext 320w, " +
+		"http://www.example.com/static/dirOut/subDir/a2-large.9bce65466314abe14fdc4f14200f7631.ext 1280w"
+	if srcset != expected {
+		t.Error("srcset should be ", expected, " instead of ", srcset)
+	}
+
+	if srcset = m.Srcset("pack", "a1"); srcset != "" {
+		t.Error("a1 has no variant with a descriptor: ", srcset)
+	}
+	if srcset = m.Srcset("single", "a1"); srcset != "" {
+		t.Error("a SingleAsset has no srcset: ", srcset)
+	}
+
+	url, err := m.templateURL("pack", "subDir/a2-large.ext")
+	if err != nil || url != "http://www.example.com/static/dirOut/subDir/a2-large.9bce65466314abe14fdc4f14200f7631.ext" {
+		t.Error("wrong planned url for a variant: ", url, err)
+	}
+}
+
+func TestDevHandlerVariants(t *testing.T) {
+	removeTestFiles()
+	createInputFiles()
+	defer removeTestFiles()
+
+	m := getVariantManagerTest()
+	m.Dev = true
+
+	w := httptest.NewRecorder()
+	m.DevHandler().ServeHTTP(w, httptest.NewRequest("GET", "/static/dirOut/subDir/a2-small.ext", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "pack-a2" {
+		t.Error("the dev handler should serve the input file of a variant: ", w.Code, w.Body.String())
+	}
+}